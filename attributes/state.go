@@ -177,7 +177,13 @@ func (i *StateIterator) EndOfLine(w runesio.Writer) {
 		return
 	}
 
-	// stop all sequences up to the last renderer at this level
+	// stop all sequences up to the last renderer at this level.
+	// Renderers up to the current one are already rendered, with their own stop sequences.
+	for current != i.current {
+		current = current.Next()
+	}
+	current = current.Next()
+
 	for current != nil {
 		attribute := current.Value.(Renderer)
 		level := attribute.Level()
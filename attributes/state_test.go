@@ -12,7 +12,7 @@ import (
 func TestState(t *testing.T) {
 	// TODO: assertions
 	w := os.Stdout
-	writer := runesio.AsRunesWriter(w)
+	writer := runesio.NewWriter(w)
 
 	t.Run("with nested levels", func(t *testing.T) {
 		state := NewState()
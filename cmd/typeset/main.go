@@ -0,0 +1,228 @@
+// Command typeset wraps text read from files or from the standard input, like fmt, fold or par,
+// with the line breaking quality of the Knuth-Plass algorithm.
+//
+// Paragraphs are separated by blank lines.
+//
+// Usage:
+//
+//	typeset [flags] [file ...]
+//
+// With no file, or when file is "-", the standard input is read.
+//
+// Example:
+//
+//	typeset -w 60 -align justify -lang fr README.txt
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/fredbi/go-typeset/linebreak"
+	"github.com/fredbi/go-typeset/terminal/ansi"
+	"github.com/fredbi/go-typeset/terminal/runes"
+	"github.com/fredbi/go-typeset/wordbreak/hyphenator"
+)
+
+type (
+	// config collects the command line flags.
+	config struct {
+		width     int
+		align     string
+		lang      string
+		hyphenate bool
+		hyphens   bool
+		tolerance float64
+		looseness int
+		eastAsian bool
+		ansi      bool
+		overfull  bool
+		prefix    string
+		files     []string
+	}
+
+	err string
+)
+
+const (
+	exitOK    = 0
+	exitError = 1
+	exitUsage = 2
+)
+
+const (
+	// ErrInvalidAlignment is reported when the -align flag is not a known alignment.
+	ErrInvalidAlignment err = "alignment must be one of: left, justify, center, right"
+
+	// ErrInvalidWidth is reported when the -w flag is not a positive width.
+	ErrInvalidWidth err = "the width must be positive"
+)
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+// run the command with some arguments, and returns the exit code.
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	cfg, err := parseFlags(args, stderr)
+	if err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOK
+		}
+
+		return exitUsage
+	}
+
+	if cfg.width < 1 {
+		fmt.Fprintf(stderr, "typeset: %v\n", ErrInvalidWidth)
+
+		return exitUsage
+	}
+
+	align, err := parseAlignment(cfg.align)
+	if err != nil {
+		fmt.Fprintf(stderr, "typeset: %v\n", err)
+
+		return exitUsage
+	}
+
+	formatter := linebreak.NewFormatter(linebreak.New(cfg.options()...), align, linebreak.UniformShape(float64(cfg.width)))
+
+	files := cfg.files
+	if len(files) == 0 {
+		files = []string{"-"}
+	}
+
+	for _, file := range files {
+		if err := formatFile(formatter, cfg, file, stdin, stdout); err != nil {
+			fmt.Fprintf(stderr, "typeset: %s: %v\n", file, err)
+
+			return exitError
+		}
+	}
+
+	return exitOK
+}
+
+func parseFlags(args []string, stderr io.Writer) (config, error) {
+	var cfg config
+
+	flags := flag.NewFlagSet("typeset", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: typeset [flags] [file ...]\n\n")
+		fmt.Fprintf(flags.Output(), "Wraps text from files, or from the standard input, with the Knuth-Plass algorithm.\n\n")
+		flags.PrintDefaults()
+	}
+
+	flags.IntVar(&cfg.width, "w", 75, "maximum width of lines, in cells")
+	flags.StringVar(&cfg.align, "align", "left", "alignment of lines: left, justify, center or right")
+	flags.StringVar(&cfg.lang, "lang", "en-US", "language used to hyphenate words, e.g. en-GB, fr, de, es")
+	flags.BoolVar(&cfg.hyphenate, "hyphenate", true, "break words at hyphenation points")
+	flags.BoolVar(&cfg.hyphens, "hyphens", true, "render a hyphen at the end of lines with a hyphenated word")
+	flags.Float64Var(&cfg.tolerance, "tolerance", 8.6, "threshold on the adjustment ratio of lines")
+	flags.IntVar(&cfg.looseness, "looseness", 0, "desired variation of the number of lines of paragraphs")
+	flags.BoolVar(&cfg.eastAsian, "east-asian", false, "measure ambiguous East-Asian characters as wide")
+	flags.BoolVar(&cfg.ansi, "ansi", true, "pass ANSI escape sequences through, or strip them when false")
+	flags.BoolVar(&cfg.overfull, "overfull", true, "allow overfull lines when a word is wider than the width, instead of failing")
+	flags.StringVar(&cfg.prefix, "prefix", "", "prefix rendered at the start of every line, e.g. \"> \"")
+
+	if err := flags.Parse(args); err != nil {
+		return cfg, err
+	}
+
+	cfg.files = flags.Args()
+
+	return cfg, nil
+}
+
+func parseAlignment(value string) (linebreak.Alignment, error) {
+	for _, align := range []linebreak.Alignment{
+		linebreak.AlignLeft, linebreak.AlignJustify, linebreak.AlignCenter, linebreak.AlignRight,
+	} {
+		if strings.EqualFold(value, align.String()) {
+			return align, nil
+		}
+	}
+
+	return linebreak.AlignLeft, ErrInvalidAlignment
+}
+
+// options for the line breaker.
+func (c config) options() []linebreak.Option {
+	opts := []linebreak.Option{
+		linebreak.WithHyphenator(hyphenator.New(hyphenator.WithLanguage(c.lang)).BreakWord),
+		linebreak.WithWordBreak(c.hyphenate),
+		linebreak.WithRenderHyphens(c.hyphens),
+		linebreak.WithTolerance(c.tolerance),
+		linebreak.WithLooseness(c.looseness),
+		linebreak.WithOverfull(c.overfull),
+	}
+
+	if c.eastAsian {
+		opts = append(opts, linebreak.WithMeasurer(func(in []rune) float64 {
+			return float64(runes.Widths(in, runes.WithEastAsian(true)))
+		}))
+	}
+
+	if c.prefix != "" {
+		opts = append(opts, linebreak.WithPrefix(c.prefix))
+	}
+
+	return opts
+}
+
+func formatFile(formatter *linebreak.Formatter, cfg config, file string, stdin io.Reader, stdout io.Writer) error {
+	var input io.Reader = stdin
+
+	if file != "-" {
+		f, err := os.Open(file)
+		if err != nil {
+			return err
+		}
+		defer func() {
+			_ = f.Close()
+		}()
+
+		input = f
+	}
+
+	if !cfg.ansi {
+		input = &stripReader{input: bufio.NewReader(input)}
+	}
+
+	return formatter.Format(stdout, input)
+}
+
+// stripReader strips ANSI escape sequences from its input, one line at a time.
+type stripReader struct {
+	input  *bufio.Reader
+	buffer []byte
+	err    error
+}
+
+func (r *stripReader) Read(p []byte) (int, error) {
+	for len(r.buffer) == 0 {
+		if r.err != nil {
+			return 0, r.err
+		}
+
+		var line string
+		line, r.err = r.input.ReadString('\n')
+		r.buffer = []byte(string(ansi.Strip([]rune(line))))
+	}
+
+	n := copy(p, r.buffer)
+	r.buffer = r.buffer[n:]
+
+	return n, nil
+}
+
+func (e err) Error() string {
+	return string(e)
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/fredbi/go-typeset/terminal/runes"
+	"github.com/stretchr/testify/require"
+)
+
+const grimm = `In olden times when wishing still helped one, there lived a king whose daughters were all beautiful; ` +
+	`and the youngest was so beautiful that the sun itself, which has seen so much, was astonished whenever it shone in her face.
+
+Close by the king's castle lay a great dark forest, and under an old lime-tree in the forest was a well, ` +
+	`and when the day was very warm, the king's child went out into the forest and sat down by the side of the cool fountain.
+`
+
+func TestRun(t *testing.T) {
+	typeset := func(t *testing.T, input string, args ...string) (string, string, int) {
+		var stdout, stderr bytes.Buffer
+		code := run(args, strings.NewReader(input), &stdout, &stderr)
+
+		return stdout.String(), stderr.String(), code
+	}
+
+	t.Run("should wrap paragraphs from stdin", func(t *testing.T) {
+		output, _, code := typeset(t, grimm, "-w", "40", "-align", "justify")
+		require.Equal(t, exitOK, code)
+
+		paragraphs := strings.Split(output, "\n\n")
+		require.Len(t, paragraphs, 2)
+
+		for _, paragraph := range paragraphs {
+			lines := strings.Split(strings.TrimSuffix(paragraph, "\n"), "\n")
+			require.Greater(t, len(lines), 2)
+
+			for _, line := range lines[:len(lines)-1] {
+				require.Equalf(t, 40, runes.StringWidth(line), "expected line %q to be justified", line)
+			}
+		}
+	})
+
+	t.Run("should read files", func(t *testing.T) {
+		file := filepath.Join(t.TempDir(), "grimm.txt")
+		require.NoError(t, os.WriteFile(file, []byte(grimm), 0o600))
+
+		fromFile, _, code := typeset(t, "", "-w", "30", file)
+		require.Equal(t, exitOK, code)
+
+		fromStdin, _, code := typeset(t, grimm, "-w", "30", "-")
+		require.Equal(t, exitOK, code)
+		require.Equal(t, fromStdin, fromFile)
+	})
+
+	t.Run("should not hyphenate words", func(t *testing.T) {
+		output, _, code := typeset(t, grimm, "-w", "20", "-hyphenate=false")
+		require.Equal(t, exitOK, code)
+
+		for _, line := range strings.Split(output, "\n") {
+			require.False(t, strings.HasSuffix(line, "-") && !strings.HasSuffix(line, "lime-"))
+		}
+	})
+
+	t.Run("should render a prefix", func(t *testing.T) {
+		output, _, code := typeset(t, grimm, "-w", "30", "-prefix", "> ")
+		require.Equal(t, exitOK, code)
+
+		for _, line := range strings.Split(strings.TrimSuffix(output, "\n"), "\n") {
+			if line == "" {
+				continue
+			}
+
+			require.True(t, strings.HasPrefix(line, "> "))
+			require.LessOrEqual(t, runes.StringWidth(line), 30)
+		}
+	})
+
+	t.Run("should strip ANSI escape sequences", func(t *testing.T) {
+		const red = "some \033[31mred\033[0m text\n"
+
+		output, _, code := typeset(t, red, "-ansi=false")
+		require.Equal(t, exitOK, code)
+		require.Equal(t, "some red text\n", output)
+
+		output, _, code = typeset(t, red)
+		require.Equal(t, exitOK, code)
+		require.Equal(t, red, output)
+	})
+
+	t.Run("should strip ANSI escape sequences from every paragraph", func(t *testing.T) {
+		const red = "some \033[31mred\033[0m text\n\nmore \033[31mred\033[0m text\n"
+
+		output, _, code := typeset(t, red, "-ansi=false")
+		require.Equal(t, exitOK, code)
+		require.Equal(t, "some red text\n\nmore red text\n", output)
+	})
+
+	t.Run("should fit every line in narrow justified text", func(t *testing.T) {
+		const lorem = "Lorem ipsum dolor sit amet, consectetur adipiscing elit, sed do eiusmod tempor.\n"
+
+		for _, input := range []string{lorem, grimm} {
+			for _, width := range []int{10, 12, 14, 20} {
+				output, _, code := typeset(t, input, "-w", strconv.Itoa(width), "-align", "justify")
+				require.Equal(t, exitOK, code)
+
+				for _, line := range strings.Split(strings.TrimSuffix(output, "\n"), "\n") {
+					require.LessOrEqualf(t, runes.StringWidth(line), width,
+						"expected line %q to fit in %d cells", line, width,
+					)
+				}
+			}
+		}
+	})
+
+	t.Run("should report errors", func(t *testing.T) {
+		_, stderr, code := typeset(t, grimm, "-align", "diagonal")
+		require.Equal(t, exitUsage, code)
+		require.Contains(t, stderr, ErrInvalidAlignment.Error())
+
+		_, stderr, code = typeset(t, grimm, "-w", "0")
+		require.Equal(t, exitUsage, code)
+		require.Contains(t, stderr, ErrInvalidWidth.Error())
+
+		_, _, code = typeset(t, grimm, "-unknown")
+		require.Equal(t, exitUsage, code)
+
+		_, stderr, code = typeset(t, "", filepath.Join(t.TempDir(), "missing.txt"))
+		require.Equal(t, exitError, code)
+		require.Contains(t, stderr, "missing.txt")
+
+		_, _, code = typeset(t, "", "-h")
+		require.Equal(t, exitOK, code)
+	})
+}
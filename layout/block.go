@@ -0,0 +1,65 @@
+package layout
+
+import (
+	"github.com/fredbi/go-typeset/linebreak"
+)
+
+type (
+	// Block is a unit of content to be laid out, such as a paragraph or a heading.
+	Block struct {
+		// Lines of the block, as rendered
+		Lines []string
+
+		// KeepWithNext discourages a page break between this block and the next one, e.g. for headings
+		KeepWithNext bool
+
+		// Rebreak optionally sets the block again with some looseness, to avoid a widow or an orphan.
+		Rebreak RebreakFunc
+	}
+
+	// RebreakFunc sets a block again, with a number of lines differing by looseness
+	// from the optimal setting, whenever possible.
+	//
+	// See linebreak.WithLooseness.
+	RebreakFunc func(looseness int) ([]string, error)
+)
+
+// ParagraphBlock builds a block from a paragraph set by a linebreak.LineBreaker.
+func ParagraphBlock(paragraph *linebreak.Paragraph) Block {
+	return Block{
+		Lines: paragraph.Strings(),
+	}
+}
+
+// HeadingBlock builds a block which is kept on the same page as the next block.
+func HeadingBlock(lines ...string) Block {
+	return Block{
+		Lines:        lines,
+		KeepWithNext: true,
+	}
+}
+
+// BreakParagraph sets a paragraph with a new linebreak.LineBreaker configured with opts,
+// and builds a block which may be set again with some looseness.
+func BreakParagraph(tokens []string, align linebreak.Alignment, shape linebreak.Shape, opts ...linebreak.Option) (Block, error) {
+	paragraph, err := linebreak.New(opts...).Break(tokens, align, shape)
+	if err != nil {
+		return Block{}, err
+	}
+
+	block := ParagraphBlock(paragraph)
+	block.Rebreak = func(looseness int) ([]string, error) {
+		loose := make([]linebreak.Option, 0, len(opts)+1)
+		loose = append(loose, opts...)
+		loose = append(loose, linebreak.WithLooseness(looseness))
+
+		paragraph, err := linebreak.New(loose...).Break(tokens, align, shape)
+		if err != nil {
+			return nil, err
+		}
+
+		return paragraph.Strings(), nil
+	}
+
+	return block, nil
+}
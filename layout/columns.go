@@ -0,0 +1,207 @@
+package layout
+
+import (
+	"strings"
+
+	"github.com/fredbi/go-typeset/attributes"
+	"github.com/fredbi/go-typeset/linebreak"
+	"github.com/fredbi/go-typeset/terminal/ansi"
+	"github.com/fredbi/go-typeset/terminal/runes"
+	"github.com/fredbi/go-typeset/terminal/runes/runesio"
+)
+
+// ErrInvalidColumns is returned when the number or the width of columns is not positive.
+const ErrInvalidColumns err = "the number and the width of columns must be positive"
+
+// Columns lays out text across several columns of the same width, rendered side by side
+// like in a newspaper.
+//
+// Column heights are balanced: the text is flowed into the shortest columns possible,
+// with the same widow and orphan control as the Paginator.
+//
+// Presentation attributes (ANSI escape sequences) may span several lines of a block.
+// The state of attributes is tracked for every column (see attributes.StateIterator):
+// attributes are closed at the edge of a column, and resumed on the next line of that column.
+type Columns struct {
+	columns int
+	width   int
+
+	*options
+}
+
+// NewColumns builds a layout with a number of columns, with a width expressed in cells.
+func NewColumns(columns, width int, opts ...Option) *Columns {
+	return &Columns{
+		columns: columns,
+		width:   width,
+		options: defaultOptions(opts),
+	}
+}
+
+// Render sets paragraphs to the width of a column, then lays them out across columns.
+//
+// Every paragraph is provided as a series of tokens.
+func (c *Columns) Render(paragraphs [][]string) ([]string, error) {
+	if c.columns <= 0 || c.width <= 0 {
+		return nil, ErrInvalidColumns
+	}
+
+	blocks := make([]Block, 0, len(paragraphs))
+	for _, tokens := range paragraphs {
+		block, err := BreakParagraph(tokens, c.align, linebreak.UniformShape(float64(c.width)), c.breakerOptions...)
+		if err != nil {
+			return nil, err
+		}
+
+		blocks = append(blocks, block)
+	}
+
+	return c.RenderBlocks(blocks)
+}
+
+// RenderBlocks lays out blocks across columns, and renders the columns side by side.
+//
+// Lines in blocks should not be wider than a column.
+func (c *Columns) RenderBlocks(blocks []Block) ([]string, error) {
+	if c.columns <= 0 || c.width <= 0 {
+		return nil, ErrInvalidColumns
+	}
+
+	columns, err := c.balance(blocks)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.render(columns), nil
+}
+
+// balance finds the shortest height such that the blocks fit in the columns.
+//
+// Heights are tried upward from an even split of all lines: widow and orphan control may require
+// more pages for a taller height, so the number of pages is not monotonic in the height.
+func (c *Columns) balance(blocks []Block) ([]Page, error) {
+	paginator := &Paginator{options: c.options}
+	total := len(paginator.flow(blocks))
+	if total == 0 {
+		return nil, nil
+	}
+
+	for height := (total + c.columns - 1) / c.columns; ; height++ {
+		paginator.height = height
+
+		columns, err := paginator.Paginate(blocks)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(columns) <= c.columns {
+			return columns, nil
+		}
+	}
+}
+
+// render columns side by side.
+//
+// The attributes of a column are closed before the gutter, and resumed on the next line of the column.
+func (c *Columns) render(columns []Page) []string {
+	var height int
+	for _, column := range columns {
+		if len(column.Lines) > height {
+			height = len(column.Lines)
+		}
+	}
+
+	states, segments := columnStates(columns)
+	gutter := strings.Repeat(" ", c.gutter)
+	lines := make([]string, 0, height)
+	var row strings.Builder
+	w := runesio.NewWriter(&row)
+
+	for i := 0; i < height; i++ {
+		row.Reset()
+
+		for k, column := range columns {
+			if k > 0 {
+				row.WriteString(gutter)
+			}
+
+			var width int
+			if i < len(column.Lines) {
+				width = visibleWidth(column.Lines[i])
+				renderColumnLine(w, states[k], segments[k][i], k == 0 && i == 0)
+			}
+
+			if k < len(columns)-1 {
+				row.WriteString(strings.Repeat(" ", max(c.width-width, 0)))
+			}
+		}
+
+		lines = append(lines, strings.TrimRight(row.String(), " "))
+	}
+
+	return lines
+}
+
+// columnStates builds the state of attributes over the lines of all columns, in the order of the flow,
+// so that attributes opened in a column may be closed in the next one.
+//
+// It yields an iterator positioned at the start of every column, and the number of renderers
+// on every line of a column.
+func columnStates(columns []Page) ([]*attributes.StateIterator, [][]int) {
+	state := attributes.NewState()
+	segments := make([][]int, len(columns))
+
+	for k, column := range columns {
+		segments[k] = make([]int, len(column.Lines))
+
+		for i, line := range column.Lines {
+			stripped := ansi.StripToken([]rune(line))
+			for _, token := range stripped {
+				state.Push(attributes.New(token.Text, token.StartSequence, token.StopSequence))
+			}
+
+			segments[k][i] = len(stripped)
+		}
+	}
+
+	states := make([]*attributes.StateIterator, len(columns))
+	iterator := state.Iterator()
+	for k := range columns {
+		start := *iterator
+		states[k] = &start
+
+		for _, count := range segments[k] {
+			for j := 0; j < count; j++ {
+				iterator.Next()
+			}
+		}
+	}
+
+	return states, segments
+}
+
+// renderColumnLine renders the next line of a column, resuming and closing its attributes.
+func renderColumnLine(w runesio.Writer, state *attributes.StateIterator, segments int, isFirst bool) {
+	if !isFirst {
+		state.StartOfLine(w)
+	}
+
+	for j := 0; j < segments && state.Next(); j++ {
+		state.Item().Render(w)
+	}
+
+	state.EndOfLine(w)
+}
+
+// visibleWidth measures the width of a line on a terminal, ignoring ANSI escape sequences.
+func visibleWidth(line string) int {
+	return runes.Widths(ansi.Strip([]rune(line)))
+}
+
+func max(a, b int) int {
+	if a > b {
+		return a
+	}
+
+	return b
+}
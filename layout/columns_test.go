@@ -0,0 +1,118 @@
+package layout
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const grimm = `In olden times when wishing still helped one, there lived a king ` +
+	`whose daughters were all beautiful, but the youngest was so beautiful ` +
+	`that the sun itself, which has seen so much, was astonished whenever it ` +
+	`shone in her face. Close by the king's castle lay a great dark forest, ` +
+	`and under an old lime-tree in the forest was a well, and when the day ` +
+	`was very warm, the king's child went out into the forest and sat down by ` +
+	`the side of the cool fountain, and when she was bored she took a golden ball, ` +
+	`and threw it up on high and caught it, and this ball was her favorite plaything.`
+
+func TestColumns(t *testing.T) {
+	const (
+		columns = 3
+		width   = 20
+		gutter  = 3
+	)
+
+	t.Run("should balance columns", func(t *testing.T) {
+		blocks := make([]Block, 0, 11)
+		for i := 1; i <= 11; i++ {
+			blocks = append(blocks, HeadingBlock(strings.Repeat("x", i)))
+			blocks[len(blocks)-1].KeepWithNext = false
+		}
+
+		lines, err := NewColumns(columns, width, WithGutter(gutter), WithSpacing(0)).RenderBlocks(blocks)
+		require.NoError(t, err)
+		require.Len(t, lines, 4)
+		require.Equal(t, "x"+strings.Repeat(" ", width-1+gutter)+
+			"xxxxx"+strings.Repeat(" ", width-5+gutter)+
+			"xxxxxxxxx", lines[0])
+		require.Equal(t, "xxxx"+strings.Repeat(" ", width-4+gutter)+
+			"xxxxxxxx", lines[3])
+	})
+
+	t.Run("should render paragraphs side by side", func(t *testing.T) {
+		paragraphs := [][]string{strings.Fields(grimm), strings.Fields(grimm)}
+
+		lines, err := NewColumns(columns, width, WithGutter(gutter)).Render(paragraphs)
+		require.NoError(t, err)
+
+		var text [columns][]string
+		for _, line := range lines {
+			require.LessOrEqual(t, visibleWidth(line), columns*width+(columns-1)*gutter)
+
+			for k := range text {
+				start := k * (width + gutter)
+				if start >= len(line) {
+					continue
+				}
+				end := start + width
+				if end > len(line) {
+					end = len(line)
+				}
+
+				text[k] = append(text[k], strings.Fields(line[start:end])...)
+			}
+		}
+
+		var words []string
+		for _, column := range text {
+			words = append(words, column...)
+		}
+		joined := strings.ReplaceAll(strings.Join(words, " "), "- ", "") // rejoin hyphenated words
+		require.Equal(t, strings.Join(append(strings.Fields(grimm), strings.Fields(grimm)...), " "), joined)
+	})
+
+	t.Run("should preserve attributes in every column", func(t *testing.T) {
+		const startRed, stop = "\033[31m", "\033[0m"
+		tokens := strings.Fields(grimm)
+		tokens[10] = startRed + tokens[10]
+		tokens[70] += stop
+
+		lines, err := NewColumns(columns, width, WithGutter(gutter)).Render([][]string{tokens})
+		require.NoError(t, err)
+
+		var colored int
+		for _, line := range lines {
+			require.Equal(t, strings.Count(line, startRed), strings.Count(line, stop),
+				"expected every column to close its attributes in line %q", line,
+			)
+			colored += strings.Count(line, startRed)
+		}
+		require.Greater(t, colored, len(lines))
+	})
+
+	t.Run("should resume attributes spanning lines in every column", func(t *testing.T) {
+		const startRed, stop = "\033[31m", "\033[0m"
+		blocks := []Block{
+			{Lines: []string{startRed + "red", "text", "still red" + stop}},
+			{Lines: []string{"plain", startRed + "red", "across", "columns" + stop}},
+		}
+
+		lines, err := NewColumns(2, 10, WithGutter(gutter), WithSpacing(0)).RenderBlocks(blocks)
+		require.NoError(t, err)
+		require.Equal(t, []string{
+			startRed + "red" + stop + strings.Repeat(" ", 7+gutter) + "plain",
+			startRed + "text" + stop + strings.Repeat(" ", 6+gutter) + startRed + "red" + stop,
+			startRed + "still red" + stop + strings.Repeat(" ", 1+gutter) + startRed + "across" + stop,
+			strings.Repeat(" ", 10+gutter) + startRed + "columns" + stop,
+		}, lines)
+	})
+
+	t.Run("should not render with invalid columns", func(t *testing.T) {
+		_, err := NewColumns(0, width).Render(nil)
+		require.ErrorIs(t, err, ErrInvalidColumns)
+
+		_, err = NewColumns(columns, 0).RenderBlocks(nil)
+		require.ErrorIs(t, err, ErrInvalidColumns)
+	})
+}
@@ -0,0 +1,10 @@
+// Package layout flows paragraphs set by the linebreak package into fixed-height boxes,
+// such as terminal pages or panes.
+//
+// The Paginator breaks a sequence of blocks (paragraphs, headings) into pages, choosing page breaks
+// which minimize the total cost for the document, in the spirit of the Knuth-Plass line breaking algorithm:
+// widows, orphans, headings separated from the text that follows, and empty lines at the bottom of pages
+// all incur some penalty.
+//
+// Columns lays out text across several columns of balanced heights, rendered side by side.
+package layout
@@ -0,0 +1,115 @@
+package layout
+
+import (
+	"github.com/fredbi/go-typeset/linebreak"
+)
+
+type (
+	// Option configures the Paginator.
+	Option func(*options)
+
+	options struct {
+		widowPenalty        float64 // penalty for the last line of a paragraph alone at the top of a page
+		orphanPenalty       float64 // penalty for the first line of a paragraph alone at the bottom of a page
+		keepWithNextPenalty float64 // penalty for a page break right after a block to be kept with the next one
+		emptyLinePenalty    float64 // cost of empty lines at the bottom of a page
+		spacing             int     // number of blank lines between blocks
+
+		gutter         int                 // number of blank cells between columns
+		align          linebreak.Alignment // alignment of paragraphs set in columns
+		breakerOptions []linebreak.Option  // options for the line breaker setting paragraphs in columns
+	}
+)
+
+// WithWidowPenalty sets the penalty for a page starting with the last line of a paragraph.
+//
+// The default is 150, like TeX's \widowpenalty.
+func WithWidowPenalty(penalty float64) Option {
+	return func(o *options) {
+		o.widowPenalty = penalty
+	}
+}
+
+// WithOrphanPenalty sets the penalty for a page ending with the first line of a paragraph.
+//
+// The default is 150, like TeX's \clubpenalty.
+func WithOrphanPenalty(penalty float64) Option {
+	return func(o *options) {
+		o.orphanPenalty = penalty
+	}
+}
+
+// WithKeepWithNextPenalty sets the penalty for a page break after a block marked KeepWithNext, such as a heading.
+//
+// The default is 10000, which prevents such breaks unless there is no other way to set the pages.
+func WithKeepWithNextPenalty(penalty float64) Option {
+	return func(o *options) {
+		o.keepWithNextPenalty = penalty
+	}
+}
+
+// WithEmptyLinePenalty sets the cost of empty lines at the bottom of a page.
+//
+// The cost of a page grows with the square of the number of empty lines at its bottom.
+// The last page is never penalized.
+//
+// The default is 10.
+func WithEmptyLinePenalty(penalty float64) Option {
+	return func(o *options) {
+		o.emptyLinePenalty = penalty
+	}
+}
+
+// WithSpacing sets the number of blank lines rendered between blocks.
+//
+// Blank lines between blocks are not rendered at the top or bottom of a page.
+//
+// The default is 1.
+func WithSpacing(lines int) Option {
+	return func(o *options) {
+		o.spacing = lines
+	}
+}
+
+// WithGutter sets the number of blank cells between columns.
+//
+// The default is 2.
+func WithGutter(cells int) Option {
+	return func(o *options) {
+		o.gutter = cells
+	}
+}
+
+// WithAlignment sets the alignment of paragraphs set in columns.
+//
+// The default is linebreak.AlignLeft.
+func WithAlignment(align linebreak.Alignment) Option {
+	return func(o *options) {
+		o.align = align
+	}
+}
+
+// WithLineBreakerOptions sets the options of the line breaker used to set paragraphs in columns.
+func WithLineBreakerOptions(opts ...linebreak.Option) Option {
+	return func(o *options) {
+		o.breakerOptions = append(o.breakerOptions, opts...)
+	}
+}
+
+func defaultOptions(opts []Option) *options {
+	o := &options{
+		widowPenalty:        150,
+		orphanPenalty:       150,
+		keepWithNextPenalty: 10000,
+		emptyLinePenalty:    10,
+		spacing:             1,
+		gutter:              2,
+		align:               linebreak.AlignLeft,
+	}
+
+	for _, apply := range opts {
+		apply(o)
+	}
+
+	return o
+}
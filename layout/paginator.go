@@ -0,0 +1,260 @@
+package layout
+
+import (
+	"math"
+)
+
+type (
+	// Paginator flows blocks into pages of a fixed height.
+	Paginator struct {
+		height int
+
+		*options
+	}
+
+	// Page is a page of rendered lines.
+	Page struct {
+		// Number of the page, starting at 1
+		Number int
+
+		// Lines on the page. There are never more lines than the height of a page.
+		Lines []string
+	}
+
+	// itemT is a line in the flow of blocks.
+	itemT struct {
+		line    string
+		block   int     // index of the block for this line
+		spacing bool    // blank line between blocks, which is never rendered at the top or bottom of a page
+		penalty float64 // penalty for a page break after this line
+	}
+
+	// pageBreakT is a feasible page break in the flow.
+	pageBreakT struct {
+		cost     float64 // total cost of the pages up to this break
+		previous int     // index of the previous page break in the flow, or -1 for the start
+	}
+
+	err string
+)
+
+// ErrInvalidHeight is returned when the height of a page is not positive.
+const ErrInvalidHeight err = "the height of a page must be positive"
+
+// NewPaginator builds a Paginator for pages with a height expressed in lines.
+func NewPaginator(height int, opts ...Option) *Paginator {
+	return &Paginator{
+		height:  height,
+		options: defaultOptions(opts),
+	}
+}
+
+// Paginate flows blocks into pages.
+//
+// Page breaks are chosen to minimize the total cost of the pages, which accounts for
+// widows, orphans, page breaks after blocks that should be kept with the next one, and empty lines at
+// the bottom of pages.
+//
+// Whenever a block with a Rebreak function is split across pages, it is set again one line shorter or longer,
+// and the better layout is retained. This avoids widows and orphans, and fills pages better.
+//
+// The input blocks are not modified.
+func (p *Paginator) Paginate(blocks []Block) ([]Page, error) {
+	if p.height <= 0 {
+		return nil, ErrInvalidHeight
+	}
+
+	blocks = append([]Block(nil), blocks...)
+	items := p.flow(blocks)
+	breaks, cost := p.pageBreaks(items)
+	tried := make([]bool, len(blocks))
+
+	for {
+		improved := false
+
+		for _, block := range p.splitBlocks(items, breaks) {
+			if tried[block] || blocks[block].Rebreak == nil {
+				continue
+			}
+			tried[block] = true
+
+			for _, looseness := range []int{-1, 1} {
+				lines, err := blocks[block].Rebreak(looseness)
+				if err != nil || len(lines) == len(blocks[block].Lines) {
+					continue
+				}
+
+				candidate := append([]Block(nil), blocks...)
+				candidate[block].Lines = lines
+				candidateItems := p.flow(candidate)
+				candidateBreaks, candidateCost := p.pageBreaks(candidateItems)
+
+				if candidateCost < cost {
+					blocks, items, breaks, cost = candidate, candidateItems, candidateBreaks, candidateCost
+					improved = true
+
+					break
+				}
+			}
+		}
+
+		if !improved {
+			break
+		}
+	}
+
+	return p.pages(items, breaks), nil
+}
+
+// flow lays out the lines of all blocks in a single sequence of items,
+// with the penalties incurred by a page break after every line.
+func (p *Paginator) flow(blocks []Block) []itemT {
+	var items []itemT
+
+	for b, block := range blocks {
+		if len(block.Lines) == 0 {
+			continue
+		}
+
+		if len(items) > 0 {
+			for i := 0; i < p.spacing; i++ {
+				items = append(items, itemT{block: b, spacing: true})
+			}
+		}
+
+		n := len(block.Lines)
+		for i, line := range block.Lines {
+			item := itemT{line: line, block: b}
+
+			if i == 0 && n > 1 {
+				// a break here leaves the first line alone at the bottom of a page
+				item.penalty += p.orphanPenalty
+			}
+
+			if i == n-2 {
+				// a break here leaves the last line alone at the top of a page
+				item.penalty += p.widowPenalty
+			}
+
+			if i == n-1 && block.KeepWithNext {
+				item.penalty += p.keepWithNextPenalty
+			}
+
+			items = append(items, item)
+		}
+	}
+
+	return items
+}
+
+// pageBreaks finds the page breaks with the least total cost.
+//
+// It returns the indices of the last item on every page, and the total cost.
+func (p *Paginator) pageBreaks(items []itemT) ([]int, float64) {
+	last := len(items) - 1
+	if last < 0 {
+		return nil, 0
+	}
+
+	// best[j+1] is the best feasible page break after item j. best[0] stands for the start of the flow.
+	best := make([]pageBreakT, len(items)+1)
+	for j := range best {
+		best[j] = pageBreakT{cost: math.Inf(1), previous: -1}
+	}
+	best[0].cost = 0
+
+	for j, item := range items {
+		if item.spacing {
+			continue
+		}
+
+		// explore the previous page breaks, for a page ending after item j
+		for i := j - 1; i >= -1; i-- {
+			if i >= 0 && items[i].spacing {
+				continue
+			}
+
+			start := p.pageStart(items, i)
+			used := j - start + 1
+			if used > p.height {
+				break
+			}
+
+			if math.IsInf(best[i+1].cost, 1) {
+				continue
+			}
+
+			cost := best[i+1].cost
+			if j < last {
+				empty := float64(p.height - used)
+				cost += item.penalty + p.emptyLinePenalty*empty*empty
+			}
+
+			if cost < best[j+1].cost {
+				best[j+1] = pageBreakT{cost: cost, previous: i}
+			}
+		}
+	}
+
+	var breaks []int
+	for j := last; j >= 0; j = best[j+1].previous {
+		breaks = append(breaks, j)
+	}
+
+	for i, k := 0, len(breaks)-1; i < k; i, k = i+1, k-1 {
+		breaks[i], breaks[k] = breaks[k], breaks[i]
+	}
+
+	return breaks, best[last+1].cost
+}
+
+// pageStart yields the first item on a page following a page break after item i,
+// skipping blank lines between blocks.
+func (p *Paginator) pageStart(items []itemT, i int) int {
+	start := i + 1
+	for start < len(items) && items[start].spacing {
+		start++
+	}
+
+	return start
+}
+
+// splitBlocks yields the blocks split across pages by some page breaks.
+func (p *Paginator) splitBlocks(items []itemT, breaks []int) []int {
+	var split []int
+
+	for _, j := range breaks {
+		if j+1 < len(items) && !items[j+1].spacing && items[j+1].block == items[j].block {
+			split = append(split, items[j].block)
+		}
+	}
+
+	return split
+}
+
+// pages renders the lines of every page.
+func (p *Paginator) pages(items []itemT, breaks []int) []Page {
+	pages := make([]Page, 0, len(breaks))
+	previous := -1
+
+	for n, j := range breaks {
+		start := p.pageStart(items, previous)
+		lines := make([]string, 0, j-start+1)
+
+		for _, item := range items[start : j+1] {
+			lines = append(lines, item.line)
+		}
+
+		pages = append(pages, Page{
+			Number: n + 1,
+			Lines:  lines,
+		})
+		previous = j
+	}
+
+	return pages
+}
+
+func (e err) Error() string {
+	return string(e)
+}
@@ -0,0 +1,124 @@
+package layout
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/fredbi/go-typeset/linebreak"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPaginator(t *testing.T) {
+	t.Run("should flow blocks into a single page", func(t *testing.T) {
+		pages, err := NewPaginator(10).Paginate([]Block{textBlock("a", 3), textBlock("b", 2)})
+		require.NoError(t, err)
+		require.Equal(t, []Page{
+			{Number: 1, Lines: []string{"a1", "a2", "a3", "", "b1", "b2"}},
+		}, pages)
+	})
+
+	t.Run("should avoid a widow", func(t *testing.T) {
+		pages, err := NewPaginator(10).Paginate([]Block{textBlock("a", 6), textBlock("b", 4)})
+		require.NoError(t, err)
+		require.Len(t, pages, 2)
+		require.Equal(t, "b2", last(pages[0].Lines))
+		require.Equal(t, []string{"b3", "b4"}, pages[1].Lines)
+	})
+
+	t.Run("should accept a widow when it is cheaper", func(t *testing.T) {
+		pages, err := NewPaginator(10, WithWidowPenalty(1)).Paginate([]Block{textBlock("a", 6), textBlock("b", 4)})
+		require.NoError(t, err)
+		require.Len(t, pages, 2)
+		require.Equal(t, []string{"b4"}, pages[1].Lines)
+	})
+
+	t.Run("should avoid an orphan", func(t *testing.T) {
+		pages, err := NewPaginator(10).Paginate([]Block{textBlock("a", 8), textBlock("b", 4)})
+		require.NoError(t, err)
+		require.Len(t, pages, 2)
+		require.Equal(t, "a8", last(pages[0].Lines))
+		require.Equal(t, "b1", pages[1].Lines[0], "a page should not start with a blank line")
+	})
+
+	t.Run("should keep a heading with the next block", func(t *testing.T) {
+		pages, err := NewPaginator(10).Paginate([]Block{
+			textBlock("a", 7),
+			HeadingBlock("Heading"),
+			textBlock("b", 5),
+		})
+		require.NoError(t, err)
+		require.Len(t, pages, 2)
+		require.Equal(t, "a7", last(pages[0].Lines))
+		require.Equal(t, []string{"Heading", "", "b1", "b2", "b3", "b4", "b5"}, pages[1].Lines)
+	})
+
+	t.Run("should set a paragraph again to avoid a widow", func(t *testing.T) {
+		var called []int
+		b := textBlock("b", 4)
+		b.Rebreak = func(looseness int) ([]string, error) {
+			called = append(called, looseness)
+
+			return textBlock("c", 4+looseness).Lines, nil
+		}
+		blocks := []Block{textBlock("a", 6), b}
+
+		pages, err := NewPaginator(10).Paginate(blocks)
+		require.NoError(t, err)
+		require.Equal(t, []int{-1}, called)
+		require.Len(t, pages, 1)
+		require.Equal(t, []string{"c1", "c2", "c3"}, pages[0].Lines[7:])
+		require.Equal(t, "b1", blocks[1].Lines[0], "input blocks should not be modified")
+	})
+
+	t.Run("should paginate paragraphs set by the line breaker", func(t *testing.T) {
+		const height = 8
+		text := strings.Fields(`In olden times when wishing still helped one, there lived a king ` +
+			`whose daughters were all beautiful, but the youngest was so beautiful ` +
+			`that the sun itself, which has seen so much, was astonished whenever it ` +
+			`shone in her face.`)
+
+		blocks := []Block{HeadingBlock("The Frog King")}
+		for i := 0; i < 3; i++ {
+			block, err := BreakParagraph(text, linebreak.AlignJustify, linebreak.UniformShape(30))
+			require.NoError(t, err)
+			blocks = append(blocks, block)
+		}
+
+		pages, err := NewPaginator(height).Paginate(blocks)
+		require.NoError(t, err)
+
+		for _, page := range pages {
+			require.LessOrEqual(t, len(page.Lines), height)
+			require.NotEmpty(t, page.Lines[0])
+			require.NotEmpty(t, last(page.Lines))
+		}
+
+		require.Len(t, pages[0].Lines, height)
+		require.Equal(t, "The Frog King", pages[0].Lines[0])
+	})
+
+	t.Run("should not paginate with an invalid height", func(t *testing.T) {
+		_, err := NewPaginator(0).Paginate([]Block{textBlock("a", 1)})
+		require.ErrorIs(t, err, ErrInvalidHeight)
+	})
+
+	t.Run("should paginate nothing", func(t *testing.T) {
+		pages, err := NewPaginator(10).Paginate(nil)
+		require.NoError(t, err)
+		require.Empty(t, pages)
+	})
+}
+
+func textBlock(prefix string, lines int) Block {
+	block := Block{Lines: make([]string, 0, lines)}
+	for i := 1; i <= lines; i++ {
+		block.Lines = append(block.Lines, fmt.Sprintf("%s%d", prefix, i))
+	}
+
+	return block
+}
+
+func last(lines []string) string {
+	return lines[len(lines)-1]
+}
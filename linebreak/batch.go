@@ -0,0 +1,81 @@
+package linebreak
+
+import (
+	"fmt"
+	"sync"
+)
+
+// ParagraphError is returned by BreakAll whenever a paragraph cannot be set.
+type ParagraphError struct {
+	// Index of the paragraph in the batch
+	Index int
+
+	Err error
+}
+
+func (e *ParagraphError) Error() string {
+	return fmt.Sprintf("paragraph %d: %v", e.Index, e.Err)
+}
+
+func (e *ParagraphError) Unwrap() error {
+	return e.Err
+}
+
+// BreakAll breaks several paragraphs in parallel, with the same alignment and shape.
+//
+// Paragraphs are returned in the order of the input, regardless of the order in which they are processed.
+// The number of paragraphs broken in parallel is bounded (see WithConcurrency).
+//
+// All paragraphs are processed, even when some cannot be set: the returned slice holds a nil Paragraph for
+// every failed paragraph, and the error is a *ParagraphError for the first failed paragraph in the batch.
+func (l *LineBreaker) BreakAll(paragraphs [][]string, align Alignment, shape Shape) ([]*Paragraph, error) {
+	return l.breakAll(len(paragraphs), func(i int) (*Paragraph, error) {
+		return l.Break(paragraphs[i], align, shape)
+	})
+}
+
+// BreakAllRunes does the same as BreakAll, but takes tokens as slices of runes.
+func (l *LineBreaker) BreakAllRunes(paragraphs [][][]rune, align Alignment, shape Shape) ([]*Paragraph, error) {
+	return l.breakAll(len(paragraphs), func(i int) (*Paragraph, error) {
+		return l.BreakRunes(paragraphs[i], align, shape)
+	})
+}
+
+func (l *LineBreaker) breakAll(n int, breakOne func(int) (*Paragraph, error)) ([]*Paragraph, error) {
+	results := make([]*Paragraph, n)
+	errs := make([]error, n)
+
+	workers := l.concurrency
+	if workers > n {
+		workers = n
+	}
+
+	indices := make(chan int)
+	var wg sync.WaitGroup
+
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for i := range indices {
+				results[i], errs[i] = breakOne(i)
+			}
+		}()
+	}
+
+	for i := 0; i < n; i++ {
+		indices <- i
+	}
+	close(indices)
+	wg.Wait()
+
+	for i, err := range errs {
+		if err != nil {
+			return results, &ParagraphError{Index: i, Err: err}
+		}
+	}
+
+	return results, nil
+}
@@ -0,0 +1,137 @@
+package linebreak
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestConcurrency(t *testing.T) {
+	const startRed, stop = "\033[31m", "\033[0m"
+
+	paragraphs := make([][]string, 0, 20)
+	for i := 0; i < 20; i++ {
+		tokens := strings.Fields(grimm)
+		tokens = tokens[i : len(tokens)-i]
+		if i%3 == 0 {
+			tokens[0] = startRed + tokens[0] + stop
+		}
+		paragraphs = append(paragraphs, tokens)
+	}
+
+	aligns := []Alignment{AlignLeft, AlignJustify, AlignCenter, AlignRight}
+	expected := make([][]string, len(paragraphs))
+	for i, tokens := range paragraphs {
+		lines, err := New().Shaped(tokens, aligns[i%len(aligns)], UniformShape(30))
+		require.NoError(t, err)
+		expected[i] = lines
+	}
+
+	t.Run("should share a LineBreaker between goroutines", func(t *testing.T) {
+		lb := New()
+		actual := make([][]string, len(paragraphs))
+		errs := make([]error, len(paragraphs))
+		var wg sync.WaitGroup
+
+		for i := range paragraphs {
+			wg.Add(1)
+
+			go func(i int) {
+				defer wg.Done()
+
+				actual[i], errs[i] = lb.Shaped(paragraphs[i], aligns[i%len(aligns)], UniformShape(30))
+			}(i)
+		}
+		wg.Wait()
+
+		for _, err := range errs {
+			require.NoError(t, err)
+		}
+		require.Equal(t, expected, actual)
+	})
+
+	t.Run("should not accumulate state across calls", func(t *testing.T) {
+		lb := New()
+		for i := 0; i < 3; i++ {
+			lines, err := lb.Shaped(paragraphs[0], aligns[0], UniformShape(30))
+			require.NoError(t, err)
+			require.Equal(t, expected[0], lines)
+		}
+	})
+
+	t.Run("should break paragraphs in batch", func(t *testing.T) {
+		for _, workers := range []int{1, 3, 100} {
+			t.Run(fmt.Sprintf("with %d workers", workers), func(t *testing.T) {
+				results, err := New(WithConcurrency(workers)).BreakAll(paragraphs, AlignLeft, UniformShape(30))
+				require.NoError(t, err)
+				require.Len(t, results, len(paragraphs))
+
+				for i, paragraph := range results {
+					lines, err := New().LeftAlignUniform(paragraphs[i], 30)
+					require.NoError(t, err)
+					require.Equal(t, lines, paragraph.Strings())
+				}
+			})
+		}
+	})
+
+	t.Run("should break paragraphs of runes in batch", func(t *testing.T) {
+		runeParagraphs := make([][][]rune, 0, len(paragraphs))
+		for _, tokens := range paragraphs {
+			runeParagraphs = append(runeParagraphs, toRunes(tokens))
+		}
+
+		results, err := New().BreakAllRunes(runeParagraphs, AlignJustify, UniformShape(30))
+		require.NoError(t, err)
+		require.Len(t, results, len(paragraphs))
+	})
+
+	t.Run("should report the first paragraph which cannot be set", func(t *testing.T) {
+		batch := [][]string{
+			strings.Fields(grimm),
+			strings.Fields("a paragraph with an extraordinarily long word"),
+			strings.Fields("some text"),
+			strings.Fields("another extraordinarily long word"),
+		}
+
+		results, err := New(WithWordBreak(false)).BreakAll(batch, AlignLeft, UniformShape(12))
+		require.Error(t, err)
+		require.ErrorIs(t, err, ErrCannotBeSet)
+
+		var perr *ParagraphError
+		require.ErrorAs(t, err, &perr)
+		require.Equal(t, 1, perr.Index)
+
+		require.Len(t, results, len(batch))
+		require.NotNil(t, results[0])
+		require.Nil(t, results[1])
+		require.NotNil(t, results[2])
+		require.Nil(t, results[3])
+	})
+
+	t.Run("should report the pass of every paragraph in batch", func(t *testing.T) {
+		batch := [][]string{
+			strings.Fields(grimm),
+			strings.Fields("a paragraph with an extraordinarily long word"),
+			strings.Fields("some text"),
+		}
+
+		results, err := New(WithWordBreak(false), WithOverfull(true)).BreakAll(batch, AlignLeft, UniformShape(12))
+		require.NoError(t, err)
+
+		require.Equal(t, PassRegular, results[0].Pass)
+		require.Equal(t, PassOverfull, results[1].Pass)
+		require.Len(t, results[1].Overfull, 1)
+		require.Equal(t, PassRegular, results[2].Pass)
+		require.Empty(t, results[2].Overfull)
+	})
+
+	t.Run("should break an empty batch", func(t *testing.T) {
+		results, err := New().BreakAll(nil, AlignLeft, UniformShape(30))
+		require.NoError(t, err)
+		require.Empty(t, results)
+	})
+}
@@ -0,0 +1,222 @@
+package linebreak
+
+import (
+	"math"
+)
+
+var (
+	_ DemeritsModel  = &KnuthPlassDemerits{}
+	_ FitnessBounder = &KnuthPlassDemerits{}
+)
+
+type (
+	// DemeritsModel scores feasible lines, so the line breaker may retain the sequence of
+	// line breaks with the fewest total demerits.
+	//
+	// A custom model may embed KnuthPlassDemerits and override some of its methods:
+	// the line breaker calls Badness and Fitness before passing their results to Demerits.
+	DemeritsModel interface {
+		// Badness measures how much a line deviates from its natural width, given its adjustment ratio.
+		Badness(ratio float64) float64
+
+		// Fitness classifies a line according to its adjustment ratio.
+		Fitness(ratio float64) Fitness
+
+		// Demerits scores a line.
+		Demerits(line LineCandidate) float64
+	}
+
+	// FitnessBounder is an optional interface for a DemeritsModel, which bounds the extra demerits
+	// incurred by the fitness classes of adjacent lines.
+	//
+	// The line breaker uses this bound to prune candidate breaks: at a given break point, a candidate with more
+	// demerits than the best candidate plus this bound can never be part of the best paragraph.
+	//
+	// Candidate breaks are not pruned with a model that doesn't implement this interface.
+	FitnessBounder interface {
+		MaxFitnessDemerits() float64
+	}
+
+	// DemeritsRule yields extra demerits for a line, on top of the demerits model.
+	DemeritsRule func(line LineCandidate) float64
+
+	// LineCandidate describes a feasible line, ending at a candidate break point.
+	LineCandidate struct {
+		// Number of the line in the paragraph, starting at 1
+		Number int
+
+		// Ratio is the adjustment ratio of the line
+		Ratio float64
+
+		// Badness of the line, as measured by the demerits model
+		Badness float64
+
+		// Fitness class of the line, as classified by the demerits model
+		Fitness Fitness
+
+		// PreviousFitness is the fitness class of the previous line
+		PreviousFitness Fitness
+
+		// Penalty at the break point, or 0 when breaking at a space
+		Penalty float64
+
+		// Flagged is true when breaking at a flagged penalty, such as a hyphen
+		Flagged bool
+
+		// PreviousFlagged is true when the previous line ends with a flagged penalty
+		PreviousFlagged bool
+
+		// Hyphenated is true when the line ends inside a word
+		Hyphenated bool
+
+		// IsLast is true for the last line of the paragraph
+		IsLast bool
+
+		// Tokens is the span of source tokens found on this line.
+		//
+		// Tokens and Words are only populated when demerits rules are configured.
+		Tokens TokenSpan
+
+		// Words are the source tokens found on this line
+		Words [][]rune
+	}
+
+	// KnuthPlassDemerits is the demerits model from the original paper by Knuth and Plass.
+	KnuthPlassDemerits struct {
+		// BadnessFactor scales the badness of a line: badness = BadnessFactor * |ratio|^3
+		BadnessFactor float64
+
+		// LinePenalty is added to the badness of every line (parameter l in the paper)
+		LinePenalty float64
+
+		// FlaggedDemerits are added whenever two consecutive lines end with a flagged break (parameter alpha)
+		FlaggedDemerits float64
+
+		// FitnessDemerits are added whenever two adjacent lines have fitness classes that are not adjacent (parameter gamma)
+		FitnessDemerits float64
+	}
+
+	// Fitness is a coarse classification of lines according to their adjustment ratio.
+	//
+	// Custom demerits models may define more fitness classes.
+	Fitness uint8
+)
+
+const (
+	// FitnessTight is for lines shrunk with an adjustment ratio below -0.5
+	FitnessTight Fitness = iota
+	// FitnessDecent is for lines with an adjustment ratio between -0.5 and 0.5
+	FitnessDecent
+	// FitnessLoose is for lines stretched with an adjustment ratio between 0.5 and 1
+	FitnessLoose
+	// FitnessVeryLoose is for lines stretched with an adjustment ratio above 1
+	FitnessVeryLoose
+)
+
+// String representation of a Fitness class.
+func (f Fitness) String() string {
+	switch f {
+	case FitnessTight:
+		return "tight"
+	case FitnessDecent:
+		return "decent"
+	case FitnessLoose:
+		return "loose"
+	case FitnessVeryLoose:
+		return "very loose"
+	default:
+		return ""
+	}
+}
+
+// Badness of a line: 100 * |ratio|^3 in TeX.
+func (m *KnuthPlassDemerits) Badness(ratio float64) float64 {
+	return m.BadnessFactor * math.Pow(math.Abs(ratio), 3)
+}
+
+// Fitness classifies lines in 4 classes: tight, decent, loose and very loose.
+func (m *KnuthPlassDemerits) Fitness(ratio float64) Fitness {
+	switch {
+	case ratio < -0.5:
+		return FitnessTight
+	case ratio <= 0.5:
+		return FitnessDecent
+	case ratio <= 1:
+		return FitnessLoose
+	default:
+		return FitnessVeryLoose
+	}
+}
+
+// Demerits of a line, combining its badness with the penalty at the break point.
+//
+// Consecutive flagged breaks and abrupt changes in fitness incur extra demerits.
+func (m *KnuthPlassDemerits) Demerits(line LineCandidate) float64 {
+	badness := m.LinePenalty + line.Badness
+	penalty := line.Penalty
+
+	var demerits float64
+	switch {
+	case penalty > 0:
+		demerits = math.Pow(badness+penalty, 2)
+	case penalty != -infinity:
+		demerits = math.Pow(badness, 2) - math.Pow(penalty, 2)
+	default:
+		demerits = math.Pow(badness, 2)
+	}
+
+	if line.Flagged && line.PreviousFlagged {
+		// penalize consecutive flagged penalty nodes
+		demerits += m.FlaggedDemerits
+	}
+
+	if abs(int(line.Fitness)-int(line.PreviousFitness)) > 1 {
+		// penalize adjacent lines whenever their fitness differ too much
+		demerits += m.FitnessDemerits
+	}
+
+	return demerits
+}
+
+// MaxFitnessDemerits bounds the demerits added whenever adjacent lines have fitness classes that are not adjacent.
+func (m *KnuthPlassDemerits) MaxFitnessDemerits() float64 {
+	return m.FitnessDemerits
+}
+
+// fitnessBound yields the bound on fitness demerits used to prune candidate breaks.
+func fitnessBound(model DemeritsModel) float64 {
+	bounder, ok := model.(FitnessBounder)
+	if !ok {
+		return math.Inf(1)
+	}
+
+	return bounder.MaxFitnessDemerits()
+}
+
+// PenalizeWidow is a DemeritsRule that adds some demerits whenever the last line
+// of a paragraph holds a single word.
+func PenalizeWidow(demerits float64) DemeritsRule {
+	return func(line LineCandidate) float64 {
+		if !line.IsLast || len(line.Words) != 1 || line.Tokens.Start == 0 {
+			return 0
+		}
+
+		return demerits
+	}
+}
+
+// PenalizeShortLastWord is a DemeritsRule that adds some demerits whenever a line,
+// other than the last one, ends with a word of at most maxLength runes (e.g. "a", "I").
+func PenalizeShortLastWord(demerits float64, maxLength int) DemeritsRule {
+	return func(line LineCandidate) float64 {
+		if line.IsLast || line.Hyphenated || len(line.Words) == 0 {
+			return 0
+		}
+
+		if len(line.Words[len(line.Words)-1]) > maxLength {
+			return 0
+		}
+
+		return demerits
+	}
+}
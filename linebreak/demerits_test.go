@@ -0,0 +1,130 @@
+package linebreak
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// fineFitness is a demerits model with more fitness classes, and a quadratic badness.
+type fineFitness struct {
+	KnuthPlassDemerits
+}
+
+func (m *fineFitness) Badness(ratio float64) float64 {
+	return m.BadnessFactor * ratio * ratio
+}
+
+func (m *fineFitness) Fitness(ratio float64) Fitness {
+	return Fitness(2*ratio + 3) // from 1 (shrunk) to 5 (very stretched)
+}
+
+// opaqueModel hides the optional interfaces of a demerits model.
+type opaqueModel struct {
+	DemeritsModel
+}
+
+func TestDemerits(t *testing.T) {
+	tokens := strings.Fields(grimm)
+
+	t.Run("should avoid a widow on the last line", func(t *testing.T) {
+		paragraph, err := New(WithWordBreak(false)).Break(tokens, AlignLeft, UniformShape(30))
+		require.NoError(t, err)
+		last := paragraph.Lines[len(paragraph.Lines)-1]
+		require.Equal(t, 1, last.Tokens.End-last.Tokens.Start)
+
+		paragraph, err = New(WithWordBreak(false), WithDemeritsRules(PenalizeWidow(1e6))).Break(tokens, AlignLeft, UniformShape(30))
+		require.NoError(t, err)
+		testRenderLines(paragraph.Strings(), 30)
+		last = paragraph.Lines[len(paragraph.Lines)-1]
+		require.Greater(t, last.Tokens.End-last.Tokens.Start, 1)
+	})
+
+	t.Run("should avoid lines ending with a one-letter word", func(t *testing.T) {
+		isShort := func(line Line) bool {
+			words := strings.Fields(line.String())
+
+			return len(words[len(words)-1]) == 1
+		}
+
+		paragraph, err := New(WithWordBreak(false)).Break(tokens, AlignLeft, UniformShape(20))
+		require.NoError(t, err)
+		var short int
+		for _, line := range paragraph.Lines {
+			if isShort(line) {
+				short++
+			}
+		}
+		require.Positive(t, short)
+
+		paragraph, err = New(WithWordBreak(false), WithDemeritsRules(PenalizeShortLastWord(1e6, 1))).Break(tokens, AlignLeft, UniformShape(20))
+		require.NoError(t, err)
+		testRenderLines(paragraph.Strings(), 20)
+		for _, line := range paragraph.Lines {
+			require.Falsef(t, isShort(line), "expected line %q not to end with a one-letter word", line)
+		}
+	})
+
+	t.Run("should use a custom demerits model", func(t *testing.T) {
+		model := &fineFitness{
+			KnuthPlassDemerits: KnuthPlassDemerits{
+				BadnessFactor:   100,
+				LinePenalty:     10,
+				FlaggedDemerits: 100,
+				FitnessDemerits: 200,
+			},
+		}
+
+		paragraph, err := New(WithDemeritsModel(model)).Break(tokens, AlignJustify, UniformShape(30))
+		require.NoError(t, err)
+		testRenderLines(paragraph.Strings(), 30)
+
+		var veryLoose bool
+		for _, line := range paragraph.Lines {
+			require.Equal(t, model.Badness(line.Ratio), line.Badness)
+			require.Equal(t, model.Fitness(line.Ratio), line.Fitness)
+			veryLoose = veryLoose || line.Fitness > FitnessVeryLoose
+		}
+		require.True(t, veryLoose, "expected some lines to be classified with a custom fitness class")
+	})
+
+	t.Run("should prune candidate breaks with the fitness demerits of a custom model", func(t *testing.T) {
+		model := &KnuthPlassDemerits{
+			BadnessFactor:   100,
+			LinePenalty:     10,
+			FlaggedDemerits: 100,
+			FitnessDemerits: 1e6,
+		}
+		require.Equal(t, 1e6, New(WithDemeritsModel(model)).fitnessBound)
+		require.True(t, math.IsInf(New(WithDemeritsModel(opaqueModel{model})).fitnessBound, 1))
+
+		for display := 20.0; display <= 35; display++ {
+			pruned, err := New(WithDemeritsModel(model)).Break(tokens, AlignJustify, UniformShape(display))
+			require.NoError(t, err)
+
+			// without pruning, the best paragraph is the same
+			unpruned, err := New(WithDemeritsModel(opaqueModel{model})).Break(tokens, AlignJustify, UniformShape(display))
+			require.NoError(t, err)
+
+			require.Equalf(t, unpruned.Demerits, pruned.Demerits, "width: %v", display)
+			require.Equal(t, unpruned.Strings(), pruned.Strings())
+		}
+	})
+
+	t.Run("should score lines like the original paper by default", func(t *testing.T) {
+		model := &KnuthPlassDemerits{BadnessFactor: 100, LinePenalty: 10, FlaggedDemerits: 100, FitnessDemerits: 200}
+
+		require.Equal(t, 100.0, model.Badness(-1))
+		require.Equal(t, FitnessTight, model.Fitness(-0.6))
+		require.Equal(t, FitnessVeryLoose, model.Fitness(1.5))
+		require.Equal(t, 110.0*110.0, model.Demerits(LineCandidate{Badness: 100, Fitness: FitnessDecent}))
+		require.Equal(t, 410.0*410.0+100, model.Demerits(LineCandidate{
+			Badness: 100, Penalty: 300, Flagged: true, PreviousFlagged: true,
+		}))
+		require.Equal(t, 10.0*10.0+200, model.Demerits(LineCandidate{
+			Penalty: -infinity, Fitness: FitnessVeryLoose, PreviousFitness: FitnessDecent,
+		}))
+	})
+}
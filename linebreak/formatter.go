@@ -0,0 +1,184 @@
+package linebreak
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"strings"
+	"unicode"
+
+	"github.com/fredbi/go-typeset/terminal/ansi"
+	"github.com/fredbi/go-typeset/terminal/runes/runesio"
+	"github.com/fredbi/go-typeset/wordbreak/tokenizer"
+)
+
+// Formatter reads a text, breaks it into paragraphs and writes the paragraphs broken into lines.
+//
+// Paragraphs are separated by blank lines. Blank lines are retained in the output.
+//
+// Attributes (e.g. ANSI escape sequences) which remain open at the end of a paragraph are closed
+// at the end of the paragraph, then resumed at the start of the next paragraph.
+//
+// A paragraph which cannot be set (e.g. with a word wider than the lines, unless WithOverfull is enabled)
+// does not stop the stream: it is written unbroken, on a single line, and ErrCannotBeSet is reported
+// once the whole text is formatted.
+type Formatter struct {
+	lb        *LineBreaker
+	tokenizer *tokenizer.Tokenizer
+	align     Alignment
+	shape     Shape
+}
+
+var stopSequence = []rune("\033[0m")
+
+// NewFormatter builds a Formatter that renders paragraphs with a LineBreaker,
+// with some alignment and paragraph shape.
+func NewFormatter(lb *LineBreaker, align Alignment, shape Shape) *Formatter {
+	return &Formatter{
+		lb:        lb,
+		tokenizer: tokenizer.New(),
+		align:     align,
+		shape:     shape,
+	}
+}
+
+// Format the text read from r and write the result to w.
+//
+// If some paragraphs cannot be set, they are written unbroken and ErrCannotBeSet is returned
+// after the whole text is formatted.
+func (f *Formatter) Format(w io.Writer, r io.Reader) error {
+	var (
+		paragraph []rune
+		open      [][]rune // the start sequences of attributes which remain open
+		unset     error    // reports paragraphs which could not be set
+	)
+
+	reader := bufio.NewReader(r)
+	writer := runesio.NewWriter(w)
+
+	for {
+		line, err := reader.ReadString('\n')
+		isEOF := errors.Is(err, io.EOF)
+		if err != nil && !isEOF {
+			return err
+		}
+
+		blank := isBlank(line)
+		if !blank {
+			paragraph = append(paragraph, []rune(line)...)
+		}
+
+		if !blank && !isEOF {
+			continue
+		}
+
+		// blank line, or end of input
+		open, err = f.formatParagraph(writer, paragraph, open)
+		switch {
+		case errors.Is(err, ErrCannotBeSet):
+			unset = err
+		case err != nil:
+			return err
+		}
+		paragraph = paragraph[:0]
+
+		if isEOF {
+			break
+		}
+
+		if _, err = writer.WriteRune('\n'); err != nil {
+			return err
+		}
+	}
+
+	return unset
+}
+
+// formatParagraph renders a single paragraph, resuming the attributes left open by previous paragraphs.
+//
+// It returns the attributes which remain open after this paragraph.
+//
+// A paragraph which cannot be set is written unbroken, and ErrCannotBeSet is returned.
+func (f *Formatter) formatParagraph(w runesio.Writer, paragraph []rune, open [][]rune) ([][]rune, error) {
+	tokens := f.tokenizer.BreakWord(paragraph)
+	if len(tokens) == 0 {
+		return open, nil
+	}
+
+	if len(open) > 0 {
+		// resume attributes
+		tokens[0] = append(concatRunes(open), tokens[0]...)
+	}
+
+	open = openSequences(tokens)
+	if len(open) > 0 {
+		// close attributes at the end of the paragraph: a single reset closes all of them
+		last := len(tokens) - 1
+		tokens[last] = append(append([]rune{}, tokens[last]...), stopSequence...)
+	}
+
+	err := f.lb.WriteShaped(w, tokens, f.align, f.shape)
+	if errors.Is(err, ErrCannotBeSet) {
+		if werr := writeUnbroken(w, tokens); werr != nil {
+			return nil, werr
+		}
+	}
+
+	return open, err
+}
+
+// writeUnbroken writes tokens on a single line, separated by a space.
+func writeUnbroken(w runesio.Writer, tokens [][]rune) error {
+	for i, token := range tokens {
+		if i > 0 {
+			if _, err := w.WriteRune(' '); err != nil {
+				return err
+			}
+		}
+
+		if _, err := w.WriteRunes(token); err != nil {
+			return err
+		}
+	}
+
+	_, err := w.WriteRune('\n')
+
+	return err
+}
+
+// openSequences tracks the start sequences which are not balanced by a stop sequence.
+func openSequences(tokens [][]rune) [][]rune {
+	var open [][]rune
+
+	for _, token := range tokens {
+		for _, stripped := range ansi.StripToken(token) {
+			if len(stripped.StartSequence) > 0 {
+				open = append(open, append([]rune{}, stripped.StartSequence...))
+			}
+
+			if len(stripped.StopSequence) > 0 && len(open) > 0 {
+				open = open[:len(open)-1]
+			}
+		}
+	}
+
+	return open
+}
+
+func concatRunes(in [][]rune) []rune {
+	var size int
+	for _, part := range in {
+		size += len(part)
+	}
+
+	out := make([]rune, 0, size)
+	for _, part := range in {
+		out = append(out, part...)
+	}
+
+	return out
+}
+
+func isBlank(line string) bool {
+	return strings.IndexFunc(line, func(r rune) bool { return !unicode.IsSpace(r) }) < 0
+}
@@ -0,0 +1,108 @@
+package linebreak
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/fredbi/go-typeset/terminal/ansi"
+	"github.com/fredbi/go-typeset/terminal/runes"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormatter(t *testing.T) {
+	t.Run("should format paragraphs separated by blank lines", func(t *testing.T) {
+		const text = "In olden times when wishing\nstill helped one, there lived a king whose daughters were all beautiful.\n" +
+			"\n\n" +
+			"Close by the king's castle lay a great dark forest.\n"
+
+		f := NewFormatter(New(), AlignLeft, UniformShape(20))
+		w := new(bytes.Buffer)
+		require.NoError(t, f.Format(w, strings.NewReader(text)))
+
+		output := w.String()
+		paragraphs := strings.Split(output, "\n\n\n")
+		require.Len(t, paragraphs, 2)
+		require.True(t, strings.HasPrefix(paragraphs[0], "In olden times when\n"))
+		require.True(t, strings.HasPrefix(paragraphs[1], "Close by the king's\n"))
+		require.True(t, strings.HasSuffix(output, "forest.\n"))
+
+		for _, line := range strings.Split(output, "\n") {
+			require.LessOrEqual(t, runes.Widths([]rune(line)), 20)
+		}
+	})
+
+	t.Run("should format the last paragraph without a trailing new line", func(t *testing.T) {
+		f := NewFormatter(New(), AlignJustify, UniformShape(30))
+		w := new(bytes.Buffer)
+		require.NoError(t, f.Format(w, strings.NewReader(grimm)))
+
+		lines := strings.Split(strings.TrimSuffix(w.String(), "\n"), "\n")
+		require.Greater(t, len(lines), 1)
+		for _, line := range lines[:len(lines)-1] {
+			require.Equal(t, 30, runes.Widths([]rune(line)))
+		}
+	})
+
+	t.Run("should resume attributes across paragraphs", func(t *testing.T) {
+		const (
+			startRed = "\033[31m"
+			stop     = "\033[0m"
+			text     = "Lorem ipsum " + startRed + "dolor sit amet\n\nconsectetur adipiscing" + stop + " elit\n"
+		)
+
+		f := NewFormatter(New(), AlignLeft, UniformShape(40))
+		w := new(bytes.Buffer)
+		require.NoError(t, f.Format(w, strings.NewReader(text)))
+
+		paragraphs := strings.Split(w.String(), "\n\n")
+		require.Len(t, paragraphs, 2)
+
+		require.Contains(t, paragraphs[0], startRed+"dolor")
+		require.True(t, strings.HasSuffix(paragraphs[0], stop))
+		require.True(t, strings.HasPrefix(paragraphs[1], startRed+"consectetur"))
+		require.Contains(t, paragraphs[1], "adipiscing"+stop)
+
+		var plain []string
+		for _, paragraph := range paragraphs {
+			var stripped []rune
+			for _, token := range ansi.StripToken([]rune(paragraph)) {
+				stripped = append(stripped, token.Text...)
+			}
+			plain = append(plain, string(stripped))
+		}
+		require.Equal(t, []string{"Lorem ipsum dolor sit amet", "consectetur adipiscing elit\n"}, plain)
+	})
+
+	t.Run("should report paragraphs that cannot be set", func(t *testing.T) {
+		f := NewFormatter(New(WithWordBreak(false)), AlignLeft, UniformShape(4))
+		require.ErrorIs(t, f.Format(new(bytes.Buffer), strings.NewReader("extraordinarily")), ErrCannotBeSet)
+	})
+
+	t.Run("should write paragraphs that cannot be set unbroken, and keep going", func(t *testing.T) {
+		f := NewFormatter(New(WithWordBreak(false)), AlignLeft, UniformShape(8))
+		w := new(bytes.Buffer)
+
+		err := f.Format(w, strings.NewReader("an extraordinarily long word\n\nsome text to be set\n"))
+		require.ErrorIs(t, err, ErrCannotBeSet)
+		require.Equal(t, "an extraordinarily long word\n\nsome\ntext to\nbe set\n", w.String())
+	})
+
+	t.Run("should close all open attributes with a single reset", func(t *testing.T) {
+		const (
+			startRed  = "\033[31m"
+			startBold = "\033[1m"
+			stop      = "\033[0m"
+		)
+
+		f := NewFormatter(New(), AlignLeft, UniformShape(40))
+		w := new(bytes.Buffer)
+		require.NoError(t, f.Format(w, strings.NewReader(startRed+"red "+startBold+"bold\n\ntext"+stop+"\n")))
+
+		paragraphs := strings.Split(w.String(), "\n\n")
+		require.Len(t, paragraphs, 2)
+		require.Equal(t, startRed+"red "+startBold+"bold"+stop, paragraphs[0])
+		require.Equal(t, 1, strings.Count(paragraphs[1], stop))
+		require.True(t, strings.HasSuffix(paragraphs[1], "text"+stop+"\n"))
+	})
+}
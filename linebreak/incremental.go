@@ -0,0 +1,335 @@
+package linebreak
+
+import (
+	"container/list"
+
+	"github.com/fredbi/go-typeset/attributes"
+)
+
+type (
+	// Incremental is a paragraph set by the LineBreaker, which may be re-broken after some edits.
+	//
+	// After an edit, the nodes and the active break points found before the edited tokens are reused,
+	// and the paragraph is re-broken only from the first line affected by the edit.
+	// The result is the same as breaking the edited paragraph from scratch.
+	//
+	// This is useful for interactive editors, which re-wrap a paragraph on every keystroke.
+	//
+	// An Incremental paragraph is not safe for concurrent use.
+	Incremental struct {
+		b         *breaker
+		states    []passStateT // the state of every pass run so far
+		breakList *breakPoint
+	}
+
+	// Edit replaces the tokens [Start, End) of a paragraph with some new tokens.
+	//
+	// An insertion is an Edit with Start == End. A deletion is an Edit without new tokens.
+	Edit struct {
+		Start  int
+		End    int
+		Tokens []string
+	}
+
+	// passStateT retains the nodes and the active break points of a line breaking pass.
+	passStateT struct {
+		pass        passT
+		nodes       []nodeT
+		attrList    *attributes.State
+		starts      []int
+		checkpoints []checkpointT
+	}
+
+	// checkpointT is a snapshot of the active break points, before exploring a node.
+	checkpointT struct {
+		index  int
+		sum    sums
+		active []*breakPoint
+	}
+)
+
+// BreakIncremental breaks a paragraph like Break, and retains the state needed to re-break it after some edits.
+func (l *LineBreaker) BreakIncremental(tokens []string, align Alignment, shape Shape) (*Incremental, error) {
+	if len(shape) == 0 {
+		return nil, ErrEmptyShape
+	}
+
+	b := l.newBreaker()
+	b.alignment = align
+	b.tokens = toRunes(tokens)
+	b.shape = b.indentedShape(shape)
+	b.lineWidths = b.buildLengths(b.shape)
+
+	p := &Incremental{b: b}
+	if err := p.rebreak(nil); err != nil {
+		return nil, err
+	}
+
+	return p, nil
+}
+
+// Edit the paragraph, and re-break it.
+//
+// If the edited paragraph cannot be set, an error is returned and the paragraph is left unchanged.
+func (p *Incremental) Edit(edit Edit) error {
+	previous := p.b.tokens
+	if edit.Start < 0 || edit.End < edit.Start || edit.End > len(previous) {
+		return ErrInvalidEdit
+	}
+
+	tokens := make([][]rune, 0, len(previous)-(edit.End-edit.Start)+len(edit.Tokens))
+	tokens = append(tokens, previous[:edit.Start]...)
+	tokens = append(tokens, toRunes(edit.Tokens)...)
+	tokens = append(tokens, previous[edit.End:]...)
+	p.b.tokens = tokens
+
+	if err := p.rebreak(&edit); err != nil {
+		p.b.tokens = previous
+		p.restoreState()
+
+		return err
+	}
+
+	return nil
+}
+
+// Tokens of the paragraph, after edits.
+func (p *Incremental) Tokens() []string {
+	tokens := make([]string, 0, len(p.b.tokens))
+	for _, token := range p.b.tokens {
+		tokens = append(tokens, string(token))
+	}
+
+	return tokens
+}
+
+// Lines of the paragraph, as rendered.
+func (p *Incremental) Lines() []string {
+	return p.b.render(p.breakList)
+}
+
+// Paragraph yields the rendered lines, with their diagnostics.
+func (p *Incremental) Paragraph() *Paragraph {
+	return p.b.paragraph(p.breakList)
+}
+
+// rebreak runs the line breaking passes, until one succeeds.
+//
+// Every pass resumes from the state it reached on the previous run, if any.
+func (p *Incremental) rebreak(edit *Edit) error {
+	b := p.b
+	states := make([]passStateT, 0, len(p.states))
+
+	for i, pass := range b.passes() {
+		var previous *passStateT
+		if edit != nil && i < len(p.states) {
+			previous = &p.states[i]
+		}
+
+		b.pass = pass
+		breakList := b.resumePass(previous, edit)
+		states = append(states, passStateT{
+			pass:        pass,
+			nodes:       b.nodes,
+			attrList:    b.attrList,
+			starts:      b.starts,
+			checkpoints: b.checkpoints,
+		})
+
+		if breakList == nil {
+			continue
+		}
+
+		// later passes are discarded: their state is not relevant anymore
+		p.states = states
+		p.breakList = breakList
+
+		return nil
+	}
+
+	return ErrCannotBeSet
+}
+
+// restoreState restores the breaker to the state of the last successful pass, after a failed edit.
+func (p *Incremental) restoreState() {
+	for i := range p.states {
+		// the nodes after the edit are shared with the failed attempt: restore the nesting of their attributes
+		p.states[i].attrList = nodeAttributes(p.states[i].nodes)
+	}
+
+	last := p.states[len(p.states)-1]
+	p.b.pass = last.pass
+	p.b.nodes = last.nodes
+	p.b.attrList = last.attrList
+}
+
+// resumePass runs a line breaking pass after an edit.
+//
+// The nodes and the active break points from the previous run of this pass are reused before the edited tokens.
+// Without a previous run, the pass runs from scratch.
+func (b *breaker) resumePass(previous *passStateT, edit *Edit) *breakPoint {
+	if previous == nil || len(previous.starts) == 0 || len(b.tokens) == 0 {
+		b.attrList = attributes.NewState()
+		b.nodes, b.starts = nil, nil
+		if len(b.tokens) > 0 {
+			b.nodes, b.starts = b.appendTokenNodes(b.openingNodes(), nil, 0, len(b.tokens))
+		}
+		b.checkpoints = make([]checkpointT, 0, len(b.tokens))
+
+		return b.breakPoints()
+	}
+
+	b.rebuildNodes(previous, edit)
+
+	// the first token with some changed nodes
+	first := minInt(edit.Start, len(previous.starts)-1, len(b.tokens)-1)
+
+	// Resume from the last checkpoint followed by a box before the changed nodes.
+	//
+	// Active break points look ahead for glues until the next box: beyond this box, they do not depend on later nodes.
+	lastBox := -1
+	for i := previous.starts[first] - 1; i >= 0; i-- {
+		if b.nodes[i].isBox() {
+			lastBox = i
+
+			break
+		}
+	}
+
+	resume := -1
+	for k := first; k >= 0; k-- {
+		if previous.checkpoints[k].index <= lastBox {
+			resume = k
+
+			break
+		}
+	}
+
+	if resume < 0 {
+		b.checkpoints = make([]checkpointT, 0, len(b.tokens))
+
+		return b.breakPoints()
+	}
+
+	checkpoint := previous.checkpoints[resume]
+	b.checkpoints = previous.checkpoints[:resume:resume] // the previous checkpoints must not be overwritten
+	b.restore(checkpoint)
+
+	return b.breakPointsFrom(checkpoint.index)
+}
+
+// rebuildNodes prepares the nodes of an edited paragraph, reusing the nodes from a previous run of the current pass.
+//
+// Only the nodes for the edited tokens are built. The nodes before and after the edit are copied.
+func (b *breaker) rebuildNodes(previous *passStateT, edit *Edit) {
+	first := minInt(edit.Start, len(previous.starts)-1, len(b.tokens)-1)
+	prefix := previous.starts[first]
+
+	nodes := make([]nodeT, prefix, len(previous.nodes)+8*len(edit.Tokens))
+	copy(nodes, previous.nodes[:prefix])
+	starts := make([]int, first, len(b.tokens))
+	copy(starts, previous.starts[:first])
+
+	// attributes of the new nodes are pushed on a scratch state: the state is rebuilt for all nodes below
+	b.attrList = attributes.NewState()
+
+	if edit.End >= len(previous.starts) {
+		// the end of the paragraph is edited
+		b.nodes, b.starts = b.appendTokenNodes(nodes, starts, first, len(b.tokens))
+		b.attrList = nodeAttributes(b.nodes)
+
+		return
+	}
+
+	nodes, starts = b.appendTokenNodes(nodes, starts, first, edit.Start+len(edit.Tokens))
+
+	// reuse the nodes after the edit, with shifted indices
+	suffix := previous.starts[edit.End]
+	shift := len(nodes) - suffix
+	delta := edit.Start + len(edit.Tokens) - edit.End
+
+	for _, start := range previous.starts[edit.End:] {
+		starts = append(starts, start+shift)
+	}
+
+	for _, node := range previous.nodes[suffix:] {
+		if node.token != noToken {
+			node.token += delta
+		}
+
+		nodes = append(nodes, node)
+	}
+
+	b.nodes = nodes
+	b.starts = starts
+	b.attrList = nodeAttributes(b.nodes)
+}
+
+// appendTokenNodes appends the nodes for the tokens [from, to), and records the index of the first node of every token.
+func (b *breaker) appendTokenNodes(nodes []nodeT, starts []int, from, to int) ([]nodeT, []int) {
+	for i := from; i < to; i++ {
+		starts = append(starts, len(nodes))
+		nodes = append(nodes, b.tokenNodes(i, b.tokens[i], i == len(b.tokens)-1)...)
+	}
+
+	return nodes, starts
+}
+
+// checkpoint records the active break points before exploring the node at index,
+// for every token starting at or before this node.
+//
+// Nothing is recorded unless the paragraph is broken incrementally.
+func (b *breaker) checkpoint(index int) {
+	var snapshot *checkpointT
+
+	for t := len(b.checkpoints); t < len(b.starts) && b.starts[t] <= index; t++ {
+		if snapshot == nil {
+			snapshot = &checkpointT{
+				index:  index,
+				sum:    *b.sum,
+				active: make([]*breakPoint, 0, b.activeNodes.Len()),
+			}
+
+			for element := b.activeNodes.Front(); element != nil; element = element.Next() {
+				snapshot.active = append(snapshot.active, element.Value.(*breakPoint))
+			}
+		}
+
+		b.checkpoints = append(b.checkpoints, *snapshot)
+	}
+}
+
+// restore the active break points from a checkpoint.
+func (b *breaker) restore(checkpoint checkpointT) {
+	sum := checkpoint.sum
+	b.sum = &sum
+	b.activeNodes = list.New()
+
+	for _, active := range checkpoint.active {
+		b.activeNodes.PushBack(active)
+	}
+}
+
+// nodeAttributes builds the state of the attributes rendered by some nodes.
+func nodeAttributes(nodes []nodeT) *attributes.State {
+	state := attributes.NewState()
+
+	for _, node := range nodes {
+		if node.isBox() && node.HasRenderer() {
+			state.Push(node.attribute)
+		}
+	}
+
+	return state
+}
+
+func minInt(values ...int) int {
+	result := values[0]
+	for _, value := range values[1:] {
+		if value < result {
+			result = value
+		}
+	}
+
+	return result
+}
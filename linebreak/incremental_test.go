@@ -0,0 +1,146 @@
+package linebreak
+
+import (
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestIncremental(t *testing.T) {
+	const startRed, stop = "\033[31m", "\033[0m"
+	tokens := strings.Fields(grimm)
+	words := append(strings.Fields(grimm), "supercalifragilisticexpialidocious", startRed+"red"+stop, "self-contained", "")
+
+	for _, toPin := range []struct {
+		name    string
+		options []Option
+	}{
+		{name: "default"},
+		{name: "with pretolerance and emergency stretch", options: []Option{WithPretolerance(100), WithEmergencyStretch(5)}},
+		{name: "with forced breaks", options: []Option{WithForceBreak(true)}},
+		{name: "with demerits rules", options: []Option{WithDemeritsRules(PenalizeWidow(500))}},
+		{name: "with looseness", options: []Option{WithLooseness(1)}},
+	} {
+		testCase := toPin
+
+		t.Run("should re-break like a full pass, "+testCase.name, func(t *testing.T) {
+			rnd := rand.New(rand.NewSource(1)) //nolint:gosec
+			lb := New(testCase.options...)
+
+			for _, align := range []Alignment{AlignLeft, AlignJustify, AlignCenter, AlignRight} {
+				current := append([]string{}, tokens...)
+				p, err := lb.BreakIncremental(current, align, UniformShape(20))
+				require.NoError(t, err)
+
+				for i := 0; i < 20; i++ {
+					edit := randomEdit(rnd, current, words)
+					expected, expectedErr := lb.Shaped(applyEdit(current, edit), align, UniformShape(20))
+					previous := p.Lines()
+
+					err := p.Edit(edit)
+					if expectedErr != nil {
+						require.ErrorIs(t, err, expectedErr)
+						require.Equal(t, current, p.Tokens())
+						require.Equal(t, previous, p.Lines())
+
+						continue
+					}
+
+					require.NoError(t, err)
+					current = applyEdit(current, edit)
+					require.Equal(t, current, p.Tokens())
+					require.Equalf(t, expected, p.Lines(), "after edit %d: %v", i, edit)
+					require.Equal(t, expected, p.Paragraph().Strings())
+				}
+			}
+		})
+	}
+
+	t.Run("should report the pass", func(t *testing.T) {
+		p, err := New(WithPretolerance(100)).BreakIncremental(tokens, AlignJustify, UniformShape(30))
+		require.NoError(t, err)
+		require.Equal(t, PassNoHyphenation, p.Paragraph().Pass)
+
+		require.NoError(t, p.Edit(Edit{Start: 3, End: 3, Tokens: []string{"supercalifragilisticexpialidocious"}}))
+		require.Equal(t, PassRegular, p.Paragraph().Pass)
+	})
+
+	t.Run("should reject invalid edits", func(t *testing.T) {
+		p, err := New().BreakIncremental(tokens, AlignLeft, UniformShape(30))
+		require.NoError(t, err)
+
+		require.ErrorIs(t, p.Edit(Edit{Start: -1, End: 0}), ErrInvalidEdit)
+		require.ErrorIs(t, p.Edit(Edit{Start: 2, End: 1}), ErrInvalidEdit)
+		require.ErrorIs(t, p.Edit(Edit{Start: 0, End: len(tokens) + 1}), ErrInvalidEdit)
+		require.Equal(t, tokens, p.Tokens())
+	})
+
+	t.Run("should leave the paragraph unchanged when an edit cannot be set", func(t *testing.T) {
+		p, err := New().BreakIncremental(tokens, AlignLeft, UniformShape(10))
+		require.NoError(t, err)
+		expected := p.Lines()
+
+		const hash = `sha256:9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08`
+		require.ErrorIs(t, p.Edit(Edit{Start: 5, End: 6, Tokens: []string{hash}}), ErrCannotBeSet)
+		require.Equal(t, tokens, p.Tokens())
+		require.Equal(t, expected, p.Lines())
+
+		require.NoError(t, p.Edit(Edit{Start: 5, End: 6, Tokens: []string{"a"}}))
+		expected, err = New().Shaped(p.Tokens(), AlignLeft, UniformShape(10))
+		require.NoError(t, err)
+		require.Equal(t, expected, p.Lines())
+	})
+}
+
+func BenchmarkIncremental(b *testing.B) {
+	long := strings.Fields(strings.Repeat(grimm+" ", 10))
+	edits := []Edit{
+		{Start: len(long) - 5, End: len(long) - 5, Tokens: []string{"typing"}},
+		{Start: len(long) - 5, End: len(long) - 4, Tokens: nil},
+	}
+
+	b.Run("full re-breaking", func(b *testing.B) {
+		lb := New()
+		tokens := long
+		b.ReportAllocs()
+		b.ResetTimer()
+
+		for n := 0; n < b.N; n++ {
+			tokens = applyEdit(tokens, edits[n%2])
+			_, _ = lb.Shaped(tokens, AlignJustify, UniformShape(40))
+		}
+	})
+
+	b.Run("incremental re-breaking", func(b *testing.B) {
+		p, err := New().BreakIncremental(long, AlignJustify, UniformShape(40))
+		require.NoError(b, err)
+		b.ReportAllocs()
+		b.ResetTimer()
+
+		for n := 0; n < b.N; n++ {
+			_ = p.Edit(edits[n%2])
+			_ = p.Lines()
+		}
+	})
+}
+
+func randomEdit(rnd *rand.Rand, tokens, words []string) Edit {
+	start := rnd.Intn(len(tokens) + 1)
+	end := start + rnd.Intn(minInt(3, len(tokens)-start)+1)
+	inserted := make([]string, rnd.Intn(3))
+	for i := range inserted {
+		inserted[i] = words[rnd.Intn(len(words))]
+	}
+
+	return Edit{Start: start, End: end, Tokens: inserted}
+}
+
+func applyEdit(tokens []string, edit Edit) []string {
+	edited := make([]string, 0, len(tokens)+len(edit.Tokens))
+	edited = append(edited, tokens[:edit.Start]...)
+	edited = append(edited, edit.Tokens...)
+
+	return append(edited, tokens[edit.End:]...)
+}
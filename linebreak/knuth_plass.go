@@ -10,7 +10,8 @@ const (
 	maxDemerit = math.MaxFloat64
 )
 
-func (l *LineBreaker) adjustmentRatio(fromBreakPoint *breakPoint, toNode nodeT, sum *sums, idealWidth float64) float64 {
+// lineWidth yields the natural width of a line, from a break point to a node.
+func lineWidth(fromBreakPoint *breakPoint, toNode nodeT, sum *sums) float64 {
 	actualWidth := sum.width - fromBreakPoint.totals.width
 
 	if toNode.isPenalty() {
@@ -18,15 +19,30 @@ func (l *LineBreaker) adjustmentRatio(fromBreakPoint *breakPoint, toNode nodeT,
 		actualWidth += toNode.width
 	}
 
+	return actualWidth
+}
+
+func (b *breaker) adjustmentRatio(fromBreakPoint *breakPoint, toNode nodeT, sum *sums, idealWidth float64) float64 {
+	actualWidth := lineWidth(fromBreakPoint, toNode, sum)
+
 	switch {
 	case actualWidth < idealWidth:
-		// need to stretch
-		stretch := sum.stretch - fromBreakPoint.totals.stretch
+		// need to stretch (in an emergency pass, some extra stretchability is added to every line)
+		stretch := sum.stretch - fromBreakPoint.totals.stretch + b.pass.emergencyStretch
 
 		if stretch > 0 {
 			return (idealWidth - actualWidth) / stretch
 		}
 
+		if b.alignment == AlignJustify {
+			// In justified text, a line without any stretchability (e.g. a single word) cannot fill its width.
+			// Like an underfull box in TeX, it remains feasible with the maximal ratio, i.e. the maximal badness,
+			// and is rendered left-aligned.
+			//
+			// With other alignments, the stretchability of ragged edges is found on every legit line.
+			return b.pass.tolerance
+		}
+
 		return infinity
 
 	case actualWidth > idealWidth:
@@ -37,7 +53,7 @@ func (l *LineBreaker) adjustmentRatio(fromBreakPoint *breakPoint, toNode nodeT,
 			return (idealWidth - actualWidth) / shrink
 		}
 
-		return infinity
+		return -infinity
 
 	default:
 		// perfect match
@@ -45,89 +61,91 @@ func (l *LineBreaker) adjustmentRatio(fromBreakPoint *breakPoint, toNode nodeT,
 	}
 }
 
-// demeritsForRatio attributes a score to the adjustment ratio, taking penalties into account.
-func (l *LineBreaker) demeritsForRatio(node nodeT, ratio float64) float64 {
-	badness := l.demerits.line + l.badness*math.Pow(math.Abs(ratio), 3)
-	penalty := node.penalty
-
-	switch {
-	case node.isPenalty() && penalty > 0:
-		return math.Pow(badness+penalty, 2)
-	case node.isPenalty() && penalty != -infinity:
-		return math.Pow(badness, 2) - math.Pow(penalty, 2)
-	default:
-		return math.Pow(badness, 2)
-	}
-}
-
 // demeritsAndClass attributes a demerits score and fitness class from a break point to a node.
-func (l *LineBreaker) demeritsAndClass(fromBreakPoint *breakPoint, toNode nodeT, ratio float64) (float64, fitnessClass) {
-	demerits := l.demeritsForRatio(toNode, ratio)
-
-	previous := l.nodes[fromBreakPoint.position]
-	if toNode.isPenalty() && toNode.flagged && previous.isPenalty() && previous.flagged {
-		// penalize consecutive flagged penalty nodes
-		demerits += l.demerits.flagged
+//
+// The score is given by the demerits model, plus any extra demerits rules.
+func (b *breaker) demeritsAndClass(fromBreakPoint *breakPoint, index int, ratio float64) (float64, Fitness) {
+	toNode := b.nodes[index]
+	previous := b.nodes[fromBreakPoint.position]
+
+	candidate := LineCandidate{
+		Number:          fromBreakPoint.line + 1,
+		Ratio:           ratio,
+		Badness:         b.model.Badness(ratio),
+		Fitness:         b.model.Fitness(ratio),
+		PreviousFitness: fromBreakPoint.fitness,
+		Flagged:         toNode.isPenalty() && toNode.flagged,
+		PreviousFlagged: previous.isPenalty() && previous.flagged,
+		Hyphenated:      b.isHyphenation(toNode),
+		IsLast:          index == len(b.nodes)-1,
 	}
 
-	currentClass := newFitnessClass(ratio)
+	if toNode.isPenalty() {
+		candidate.Penalty = toNode.penalty
+	}
 
-	// add demerits due to fitness class whenever the fitness of 2 adjacent lines differ too much
-	if currentClass.isAwayFrom(fromBreakPoint.fitness) {
-		demerits += l.demerits.fitness
+	if len(b.rules) > 0 {
+		candidate.Tokens = tokenSpan(b.nodes[fromBreakPoint.position : index+1])
+		candidate.Words = b.tokens[candidate.Tokens.Start:candidate.Tokens.End]
 	}
 
-	demerits += fromBreakPoint.totalDemerits
+	demerits := b.model.Demerits(candidate)
+	for _, rule := range b.rules {
+		demerits += rule(candidate)
+	}
 
-	return demerits, currentClass
+	return demerits + fromBreakPoint.totalDemerits, candidate.Fitness
 }
 
 // breakPoints yields an ordered linked-list breakpoints
-func (l *LineBreaker) breakPoints() *breakPoint {
+func (b *breaker) breakPoints() *breakPoint {
 	// reset state
-	l.sum = new(sums)
-	l.activeNodes = list.New()
-	l.activeNodes.PushBack(newBreakPoint(0, 0, 0, 0, fitnessClassOne, sums{}, nil)) // first empty node starting a paragraph
-	startNode := l.findStartNode()                                                  // Baskerville version - should be 1 in normal cases
+	b.sum = new(sums)
+	b.activeNodes = list.New()
+	b.activeNodes.PushBack(newBreakPoint(0, 0, 0, 0, FitnessDecent, sums{}, nil)) // first empty node starting a paragraph
+	startNode := b.findStartNode()                                                // Baskerville version - should be 1 in normal cases
+
+	return b.breakPointsFrom(startNode)
+}
 
-	for i, node := range l.nodes[startNode:] {
+// breakPointsFrom explores the nodes starting at some index, given the active break points found so far.
+func (b *breaker) breakPointsFrom(startNode int) *breakPoint {
+	for i, node := range b.nodes[startNode:] {
 		index := startNode + i
+		b.checkpoint(index)
 
 		switch {
 		case node.isBox():
-			l.sum.width += node.width // accumulate the total width of word
+			b.sum.width += node.width // accumulate the total width of word
 		case node.isGlue():
-			if index > 0 && l.nodes[index-1].nodeType == nodeTypeBox {
-				l.mainLoop(index) // explore a glue following a word
+			if index > 0 && b.nodes[index-1].nodeType == nodeTypeBox {
+				b.mainLoop(index) // explore a glue following a word
 			}
 
-			l.sum.Add(node.sums)
+			b.sum.Add(node.sums)
 
 		case node.isPenalty() && node.penalty != infinity:
-			l.mainLoop(index) // explore a penalty
+			b.mainLoop(index) // explore a penalty
 		}
 	}
 
-	if l.activeNodes.Len() > 0 {
-		nodeWithMinDemerits := l.findBestBreak()
-
-		return reverseBreakPoints(nodeWithMinDemerits)
-	}
-
-	if l.looseness == 0 {
+	if b.activeNodes.Len() == 0 {
 		return nil
 	}
 
-	// choose appropriate node
-	// TODO: implem looseness != 0 ("choose the appropriate active node")
+	nodeWithMinDemerits := b.findBestBreak()
+	if b.looseness != 0 {
+		// choose the appropriate active node
+		nodeWithMinDemerits = b.findLooseBreak(nodeWithMinDemerits)
+	}
 
-	return nil
+	return reverseBreakPoints(nodeWithMinDemerits)
 }
 
 // findStartNode skips starting glues (i.e. indentations) or penalties.
 // TODO: remove?? (Baskerville version only)
-func (l *LineBreaker) findStartNode() (start int) {
-	for _, node := range l.nodes {
+func (b *breaker) findStartNode() (start int) {
+	for _, node := range b.nodes {
 		switch node.nodeType {
 		case nodeTypeBox:
 			return start
@@ -144,12 +162,12 @@ func (l *LineBreaker) findStartNode() (start int) {
 	return start
 }
 
-func (l *LineBreaker) findBestBreak() *breakPoint {
+func (b *breaker) findBestBreak() *breakPoint {
 	nodeWithMinDemerits := &breakPoint{
 		totalDemerits: maxDemerit,
 	}
 
-	for element := l.activeNodes.Front(); element != nil; element = element.Next() {
+	for element := b.activeNodes.Front(); element != nil; element = element.Next() {
 		node := element.Value.(*breakPoint)
 
 		if node.totalDemerits < nodeWithMinDemerits.totalDemerits {
@@ -160,10 +178,34 @@ func (l *LineBreaker) findBestBreak() *breakPoint {
 	return nodeWithMinDemerits
 }
 
-func (l *LineBreaker) sumFromNode(index int) sums {
-	sum := *l.sum
+// findLooseBreak chooses the active node with a number of lines as close as possible
+// to the optimal number of lines, plus the desired looseness.
+//
+// Among the active nodes with the same number of lines, the node with the fewest total demerits is retained.
+func (b *breaker) findLooseBreak(best *breakPoint) *breakPoint {
+	var delta int // the achieved variation of the number of lines, in the direction of looseness
+	lines := best.line
+
+	for element := b.activeNodes.Front(); element != nil; element = element.Next() {
+		node := element.Value.(*breakPoint)
+		lineDelta := node.line - lines
+
+		switch {
+		case (b.looseness <= lineDelta && lineDelta < delta) || (delta < lineDelta && lineDelta <= b.looseness):
+			delta = lineDelta
+			best = node
+		case lineDelta == delta && node.totalDemerits < best.totalDemerits:
+			best = node
+		}
+	}
+
+	return best
+}
+
+func (b *breaker) sumFromNode(index int) sums {
+	sum := *b.sum
 
-	for i, node := range l.nodes[index:] {
+	for i, node := range b.nodes[index:] {
 		if node.isGlue() {
 			sum.Add(node.sums)
 
@@ -179,82 +221,165 @@ func (l *LineBreaker) sumFromNode(index int) sums {
 }
 
 // exploreForNode is referred to as "the main loop" in Knuth & Plass.
-func (l *LineBreaker) mainLoop(index int) {
-	node := l.nodes[index]
-	activeElement := l.activeNodes.Front()
+func (b *breaker) mainLoop(index int) {
+	node := b.nodes[index]
+	if !node.isForcedBreak() && b.isEmptyTail(index) {
+		// no break may occur here, since the next line would be empty
+		return
+	}
+
+	activeElement := b.activeNodes.Front()
 
 	var (
 		currentLine int // will range over lines starting from 1
-		candidates  map[fitnessClass]*breakPoint
+		candidates  candidatesT
+		fallback    candidateT
+		inserted    bool
 	)
 
-	lowestDemerits := maxDemerit
 	for activeElement != nil {
 		candidates = defaultCandidates() // set candidates with infinite demerits
+		lowestDemerits := maxDemerit
 
 		// break points up to the current line
 		for activeElement != nil {
 			active := activeElement.Value.(*breakPoint)
 			next := activeElement.Next()
 			currentLine = active.line + 1
-			ratio := l.adjustmentRatio(active, node, l.sum, l.idealWidth(currentLine))
+			ratio := b.adjustmentRatio(active, node, b.sum, b.idealWidth(currentLine))
+
+			deactivate := ratio < -1 || node.isForcedBreak()
 
-			if ratio < -1 || node.isForcedBreak() {
+			if deactivate {
 				// deactivate an undesirable break or a forced line break
-				l.activeNodes.Remove(activeElement)
+				b.activeNodes.Remove(activeElement)
 			}
 
-			if ratio >= -1 && ratio <= l.tolerance {
-				// update candidate
-				demerits, currentClass := l.demeritsAndClass(active, node, ratio)
+			switch {
+			case b.isEmptyLine(active, index):
+				// a line must hold some content
+
+			case ratio >= -1 && (ratio <= b.pass.tolerance || b.pass.final):
+				// update candidate.
+				//
+				// In the final pass, underfull lines are feasible with the maximal ratio, so that
+				// overfull lines are only produced whenever no other break may be found.
+				feasible := minf(ratio, b.pass.tolerance)
+				demerits, currentClass := b.demeritsAndClass(active, index, feasible)
 				lowestDemerits = minf(lowestDemerits, demerits)
 
-				if demerits < candidates[currentClass].totalDemerits {
-					candidates[currentClass] = active
+				if demerits < candidates.demerits(currentClass) {
+					candidates = candidates.set(currentClass, candidateT{
+						active:        active,
+						totalDemerits: demerits,
+						ratio:         feasible,
+					})
 				}
 			}
 
-			// ratio > l.tolerance is not considered feasible
+			if deactivate && !b.isEmptyLine(active, index) {
+				// remember the deactivated node which yields the least overfull line, in case no active node remains
+				candidate := candidateT{
+					active:        active,
+					totalDemerits: active.totalDemerits,
+					ratio:         ratio,
+					overflow:      lineWidth(active, node, b.sum) - b.idealWidth(currentLine),
+				}
+
+				if isBetterFallback(candidate, fallback) {
+					fallback = candidate
+				}
+			}
+
+			// ratio > b.tolerance is not considered feasible
 
 			activeElement = next
 
-			if activeElement != nil && active.line >= currentLine {
-				// stop iterating to add new candidates
+			if activeElement != nil && activeElement.Value.(*breakPoint).line >= currentLine {
+				// stop iterating to add new candidates: the next active node starts another line
 				break
 			}
 		}
 
 		if lowestDemerits < maxDemerit {
-			l.insertNewActiveBreak(activeElement, index, lowestDemerits, candidates)
+			b.insertNewActiveBreak(activeElement, index, lowestDemerits, candidates)
+			inserted = true
+		}
+	}
+
+	if b.pass.final && !inserted && b.activeNodes.Len() == 0 && fallback.active != nil {
+		// In the final pass, the paragraph is never left without an active node:
+		// a break is forced from a deactivated node, with artificial demerits, and the line is overfull.
+		candidates = defaultCandidates()
+		candidates[FitnessDecent] = fallback
+		b.insertNewActiveBreak(nil, index, fallback.totalDemerits, candidates)
+	}
+}
+
+// isEmptyLine tells if the line between an active break point and a node holds no box with some content,
+// i.e. only discardable or structural nodes.
+func (b *breaker) isEmptyLine(active *breakPoint, index int) bool {
+	for _, node := range b.nodes[active.position:index] {
+		if node.isBox() && len(node.value) > 0 {
+			return false
 		}
 	}
+
+	return true
 }
 
-func (l *LineBreaker) insertNewActiveBreak(activeElement *list.Element, index int, lowestDemerits float64, candidates map[fitnessClass]*breakPoint) {
-	sum := l.sumFromNode(index)
+// isEmptyTail tells if no box with some content follows a node, up to the next forced break.
+func (b *breaker) isEmptyTail(index int) bool {
+	for _, node := range b.nodes[index+1:] {
+		switch {
+		case node.isBox() && len(node.value) > 0:
+			return false
+		case node.isForcedBreak():
+			return true
+		}
+	}
+
+	return true
+}
+
+// isBetterFallback tells if a deactivated node is a better fallback than the current one for a forced break:
+// a line that fits is preferred, then the least overfull line, then the fewest demerits.
+func isBetterFallback(candidate, fallback candidateT) bool {
+	switch {
+	case fallback.active == nil:
+		return true
+	case candidate.overflow <= 0 && fallback.overflow <= 0, candidate.overflow == fallback.overflow:
+		return candidate.totalDemerits < fallback.totalDemerits
+	default:
+		return candidate.overflow < fallback.overflow
+	}
+}
+
+func (b *breaker) insertNewActiveBreak(activeElement *list.Element, index int, lowestDemerits float64, candidates candidatesT) {
+	sum := b.sumFromNode(index)
 
 	for class, candidate := range candidates {
-		if candidate.totalDemerits >= maxDemerit || candidate.totalDemerits > lowestDemerits+l.demerits.fitness {
-			// skip default candidate
+		if candidate.totalDemerits >= maxDemerit || candidate.totalDemerits > lowestDemerits+b.fitnessBound {
+			// skip default candidate, or candidates with a poor rating
 			continue
 		}
 
 		newBreak := newBreakPoint(
 			index,                                    // break at node index
 			candidate.totalDemerits, candidate.ratio, // ratings for this break point
-			candidate.line+1,
-			class,
-			sum,       // totals after this node
-			candidate, // link to the previous candidate breakpoint
+			candidate.active.line+1,
+			Fitness(class),
+			sum,              // totals after this node
+			candidate.active, // link to the previous candidate breakpoint
 		)
 
 		if activeElement != nil {
-			_ = l.activeNodes.InsertBefore(newBreak, activeElement)
+			_ = b.activeNodes.InsertBefore(newBreak, activeElement)
 
-			return
+			continue
 		}
 
-		_ = l.activeNodes.PushBack(newBreak)
+		_ = b.activeNodes.PushBack(newBreak)
 	}
 }
 
@@ -279,10 +404,10 @@ func reverseBreakPoints(brk *breakPoint) *breakPoint {
 // getIdealWidth retrieves the constraint on the line length.
 //
 // NOTE: currentLine starts at 1.
-func (l *LineBreaker) idealWidth(currentLine int) float64 {
-	if currentLine < len(l.lineWidths)+1 {
-		return l.lineWidths[currentLine-1]
+func (b *breaker) idealWidth(currentLine int) float64 {
+	if currentLine < len(b.lineWidths)+1 {
+		return b.lineWidths[currentLine-1]
 	}
 
-	return l.lineWidths[len(l.lineWidths)-1]
+	return b.lineWidths[len(b.lineWidths)-1]
 }
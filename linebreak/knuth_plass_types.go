@@ -13,6 +13,8 @@ type (
 		flagged   bool
 		value     []rune
 		attribute attributes.Renderer // attributes such as color, italic, bold ...
+		token     int                 // index of the source token for this node, or noToken
+		offset    int                 // for boxes, offset of the value in the source token, in runes
 
 		sums
 	}
@@ -24,16 +26,28 @@ type (
 	}
 
 	breakPoint struct {
-		position      int          // index of this breakpoint (0 = start of paragraph)
-		line          int          // the line ending at this breakpoint
-		fitness       fitnessClass // fitness class of the line ending at this breakpoint
-		totalDemerits float64      // minimum total demerits up to this breakpoint
-		totals        sums         // total width, stretch and shrink used to calculate adjustment ratios
-		ratio         float64      // informative: the adjustment ratio at this breakpoint
-		previous      *breakPoint  // pointer to the best node for the preceding breakpoint
+		position      int         // index of this breakpoint (0 = start of paragraph)
+		line          int         // the line ending at this breakpoint
+		fitness       Fitness     // fitness class of the line ending at this breakpoint
+		totalDemerits float64     // minimum total demerits up to this breakpoint
+		totals        sums        // total width, stretch and shrink used to calculate adjustment ratios
+		ratio         float64     // informative: the adjustment ratio at this breakpoint
+		previous      *breakPoint // pointer to the best node for the preceding breakpoint
 		next          *breakPoint
 	}
 
+	// candidateT is a feasible break point at the node being explored,
+	// with the best active break point found so far for some fitness class.
+	candidateT struct {
+		active        *breakPoint // the active break point starting the line
+		totalDemerits float64     // total demerits when breaking from the active break point
+		ratio         float64     // adjustment ratio of the line
+		overflow      float64     // width of the line in excess of its desired width, for a forced break
+	}
+
+	// candidatesT holds a candidate for every fitness class.
+	candidatesT []candidateT
+
 	nodeType uint8
 
 	demeritsT struct {
@@ -46,10 +60,10 @@ type (
 		ratio    float64
 		nodes    []nodeT
 		position int
+		line     int  // line number, starting at 1
+		isLast   bool // last line in the paragraph
 	}
 
-	fitnessClass int
-
 	err string
 
 	tokenState struct {
@@ -64,6 +78,15 @@ type (
 const (
 	// ErrCannotBeSet indicates that the display constraints cannot be met with the given tolerance parameter.
 	ErrCannotBeSet err = "paragraph cannot be set with the given tolerance"
+
+	// ErrEmptyShape indicates that the shape of a paragraph does not specify any line.
+	ErrEmptyShape err = "paragraph shape must specify at least one line"
+
+	// ErrInvalidEdit indicates that an edit refers to tokens out of the range of the paragraph.
+	ErrInvalidEdit err = "edit is out of the range of the paragraph tokens"
+
+	// ErrInvalidSpan indicates that a span of tokens is out of the range of the tokens.
+	ErrInvalidSpan err = "span is out of the range of the tokens"
 )
 
 const (
@@ -74,16 +97,7 @@ const (
 	nodeTypeBox
 )
 
-const (
-	// fitness classes
-
-	fitnessClassZero  fitnessClass = iota // "tight lines"
-	fitnessClassOne                       // "normal lines"
-	fitnessClassTwo                       // "loose lines"
-	fitnessClassThree                     // "very loose lines"
-)
-
-func newBreakPoint(position int, demerits float64, ratio float64, line int, fitness fitnessClass, totals sums, previous *breakPoint) *breakPoint {
+func newBreakPoint(position int, demerits float64, ratio float64, line int, fitness Fitness, totals sums, previous *breakPoint) *breakPoint {
 	return &breakPoint{
 		position:      position,
 		totalDemerits: demerits,
@@ -99,6 +113,7 @@ func newBreakPoint(position int, demerits float64, ratio float64, line int, fitn
 func newGlue(width, stretch, shrink float64) nodeT {
 	return nodeT{
 		nodeType: nodeTypeGlue,
+		token:    noToken,
 		sums: sums{
 			width:   width,
 			stretch: stretch,
@@ -111,6 +126,7 @@ func newBox(width float64, value []rune, attribute attributes.Renderer) nodeT {
 	return nodeT{
 		nodeType: nodeTypeBox,
 		value:    value,
+		token:    noToken,
 		sums: sums{
 			width: width,
 		},
@@ -121,6 +137,7 @@ func newBox(width float64, value []rune, attribute attributes.Renderer) nodeT {
 func newPenalty(width float64, penalty float64, flagged bool) nodeT {
 	return nodeT{
 		nodeType: nodeTypePenalty,
+		token:    noToken,
 		sums: sums{
 			width: width,
 		},
@@ -129,27 +146,33 @@ func newPenalty(width float64, penalty float64, flagged bool) nodeT {
 	}
 }
 
-// newFitnessClass establish a coarse fitness classification according to the cost ratio.
-func newFitnessClass(ratio float64) fitnessClass {
-	switch {
-	case ratio < -0.5:
-		return fitnessClassZero
-	case ratio <= 0.5:
-		return fitnessClassOne
-	case ratio <= 1:
-		return fitnessClassTwo
-	default:
-		return fitnessClassThree
+func defaultCandidates() candidatesT {
+	candidates := make(candidatesT, FitnessVeryLoose+1)
+	for class := range candidates {
+		candidates[class] = candidateT{totalDemerits: maxDemerit}
+	}
+
+	return candidates
+}
+
+// set the candidate for a fitness class, possibly growing the set for custom fitness classes.
+func (c candidatesT) set(class Fitness, candidate candidateT) candidatesT {
+	for int(class) >= len(c) {
+		c = append(c, candidateT{totalDemerits: maxDemerit})
 	}
+
+	c[class] = candidate
+
+	return c
 }
 
-func defaultCandidates() map[fitnessClass]*breakPoint {
-	return map[fitnessClass]*breakPoint{
-		fitnessClassZero:  newBreakPoint(0, maxDemerit, 0, 0, fitnessClassZero, sums{}, nil),
-		fitnessClassOne:   newBreakPoint(0, maxDemerit, 0, 0, fitnessClassOne, sums{}, nil),
-		fitnessClassTwo:   newBreakPoint(0, maxDemerit, 0, 0, fitnessClassTwo, sums{}, nil),
-		fitnessClassThree: newBreakPoint(0, maxDemerit, 0, 0, fitnessClassThree, sums{}, nil),
+// demerits of the candidate for a fitness class.
+func (c candidatesT) demerits(class Fitness) float64 {
+	if int(class) >= len(c) {
+		return maxDemerit
 	}
+
+	return c[class].totalDemerits
 }
 
 func (e err) Error() string {
@@ -209,10 +232,6 @@ func (n nodeT) HasRenderer() bool {
 	return n.attribute != nil
 }
 
-func (f fitnessClass) isAwayFrom(g fitnessClass) bool {
-	return abs(int(f)-int(g)) > 1
-}
-
 func (s *tokenState) Start(text []rune) {
 	if !s.isStarted {
 		s.isStarted = true
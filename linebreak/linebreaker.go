@@ -16,26 +16,59 @@ type (
 	// LineBreaker breaks a paragraph into lines under line width constraints.
 	//
 	// It implements the classical Knuth-Plass algorithm.
+	//
+	// A LineBreaker only holds its configuration: it may be used concurrently by several goroutines.
 	LineBreaker struct {
+		spaceWidth   float64
+		hyphenWidth  float64
+		spaceStretch float64
+		spaceShrink  float64
+		fitnessBound float64 // bound on the demerits incurred by the fitness classes of adjacent lines
+
+		*options
+	}
+
+	// breaker holds the state of the line breaking algorithm while setting a single paragraph.
+	breaker struct {
+		tokens      [][]rune
 		nodes       []nodeT
 		lineWidths  []float64
 		sum         *sums
 		activeNodes *list.List
-		spaceWidth  float64
-		hyphenWidth float64
 		attrList    *attributes.State
-		// spaceStretch float64
-		// spaceShrink  float64
+		alignment   Alignment
+		shape       Shape
+		pass        passT // the current pass, or the pass which succeeded in setting the paragraph
 
-		*options
+		// state retained to re-break a paragraph incrementally
+		starts      []int         // index of the first node of every token
+		checkpoints []checkpointT // active break points at the first node of every token
+
+		*LineBreaker
 	}
+
+	// Alignment specifies how lines are rendered within the width of a paragraph.
+	Alignment uint8
+)
+
+const (
+	// AlignLeft renders left-aligned lines (ragged right).
+	AlignLeft Alignment = iota
+	// AlignJustify renders lines that fill the width of the paragraph, except for the last line.
+	AlignJustify
+	// AlignCenter renders centered lines.
+	AlignCenter
+	// AlignRight renders right-aligned lines (ragged left).
+	AlignRight
 )
 
 const (
 	flaggedPenalty   = true
 	unflaggedPenalty = false
 	noWidth          = 0.0
+	noStretch        = 0.0
 	noShrink         = 0.0
+	noToken          = -1 // structural nodes do not originate from a source token
 )
 
 var (
@@ -46,8 +79,7 @@ var (
 // New line breaker.
 func New(opts ...Option) *LineBreaker {
 	l := &LineBreaker{
-		attrList: attributes.NewState(),
-		options:  defaultOptions(opts),
+		options: defaultOptions(opts),
 	}
 
 	l.spaceWidth = l.scale(l.measurer(space))
@@ -66,9 +98,13 @@ func New(opts ...Option) *LineBreaker {
 		l.punctuator = p.BreakWord
 	}
 
-	// NOTE: this is for center & justify (not implemented for now)
-	// l.spaceStretch = min(1, int(float64(l.spaceWidth*l.space.width)/float64(l.space.stretch)))
-	// l.spaceShrink = min(1, int(float64(l.spaceWidth*l.space.width)/float64(l.space.shrink)))
+	// stretchability of spaces between words, for justified text.
+	//
+	// NOTE: on a fixed-width terminal, a space cannot be shrunk below a single cell.
+	// Shrinkability is therefore given by the glueShrink setting, which defaults to 0.
+	l.spaceStretch = l.spaceWidth * l.space.stretch / l.space.width
+	l.spaceShrink = l.glueShrink
+	l.fitnessBound = fitnessBound(l.model)
 
 	return l
 }
@@ -76,30 +112,123 @@ func New(opts ...Option) *LineBreaker {
 // LeftAlignUniform left-align a series of tokens that compose a paragraph,
 // rendering multiple lines of uniform length maxLength.
 //
-// TODO: move to [][]rune -> []rune
+// See ShapedRunes and WriteShaped for a rune-native API.
 func (l *LineBreaker) LeftAlignUniform(tokens []string, maxWidth float64) ([]string, error) {
-	// 1. build a model that represent the tokens in terms of glue/box/penalty nodes
-	l.nodes = l.leftAlignedNodes(tokens)
+	return l.format(AlignLeft, tokens, UniformShape(maxWidth))
+}
 
-	// 2. build a model for desired widths for lines
-	l.lineWidths = l.buildUniformLengths(maxWidth)
+// Justify a series of tokens that compose a paragraph,
+// rendering multiple lines of uniform length maxWidth.
+//
+// Spaces between words are stretched so that every line exactly fills maxWidth,
+// except for the last line of the paragraph, which is left-aligned.
+func (l *LineBreaker) Justify(tokens []string, maxWidth float64) ([]string, error) {
+	return l.format(AlignJustify, tokens, UniformShape(maxWidth))
+}
+
+// Center a series of tokens that compose a paragraph,
+// rendering multiple lines of uniform length maxWidth.
+//
+// Line breaks are chosen to balance the ragged edges on both sides of the paragraph.
+func (l *LineBreaker) Center(tokens []string, maxWidth float64) ([]string, error) {
+	return l.format(AlignCenter, tokens, UniformShape(maxWidth))
+}
 
-	// 3. compute a chained-list of break points
-	breakList := l.breakPoints()
+// RightAlign right-align a series of tokens that compose a paragraph,
+// rendering multiple lines of uniform length maxWidth.
+func (l *LineBreaker) RightAlign(tokens []string, maxWidth float64) ([]string, error) {
+	return l.format(AlignRight, tokens, UniformShape(maxWidth))
+}
+
+// Shaped breaks a series of tokens that compose a paragraph into lines of varying widths,
+// as specified by the shape of the paragraph.
+//
+// Lines are rendered with their indentation and aligned within their own width.
+//
+// Example:
+//
+//	// a hanging indent: all lines but the first are indented by 4 cells
+//	lines, err := lb.Shaped(tokens, AlignLeft, Shape{{Width: 40}, {Indent: 4, Width: 36}})
+func (l *LineBreaker) Shaped(tokens []string, align Alignment, shape Shape) ([]string, error) {
+	return l.format(align, tokens, shape)
+}
+
+// ShapedRunes does the same as Shaped, but takes tokens as slices of runes and
+// returns lines as slices of runes.
+//
+// Tokens may be fed directly from a word breaker such as tokenizer.Tokenizer.
+func (l *LineBreaker) ShapedRunes(tokens [][]rune, align Alignment, shape Shape) ([][]rune, error) {
+	b := l.newBreaker()
+	breakList, err := b.breakParagraph(align, tokens, shape)
+	if err != nil {
+		return nil, err
+	}
+
+	return b.renderRunes(breakList), nil
+}
+
+// WriteShaped does the same as ShapedRunes, but writes the lines to a runesio.Writer.
+//
+// Every line is terminated by a new line.
+//
+// Nothing is written if the paragraph cannot be set.
+func (l *LineBreaker) WriteShaped(w runesio.Writer, tokens [][]rune, align Alignment, shape Shape) error {
+	b := l.newBreaker()
+	breakList, err := b.breakParagraph(align, tokens, shape)
+	if err != nil {
+		return err
+	}
+
+	return b.renderTo(w, breakList)
+}
+
+func (l *LineBreaker) format(align Alignment, tokens []string, shape Shape) ([]string, error) {
+	b := l.newBreaker()
+	breakList, err := b.breakParagraph(align, toRunes(tokens), shape)
+	if err != nil {
+		return nil, err
+	}
+
+	return b.render(breakList), nil
+}
+
+// newBreaker prepares the state to set a new paragraph.
+func (l *LineBreaker) newBreaker() *breaker {
+	return &breaker{
+		attrList:    attributes.NewState(),
+		LineBreaker: l,
+	}
+}
+
+func (b *breaker) breakParagraph(align Alignment, tokens [][]rune, shape Shape) (*breakPoint, error) {
+	if len(shape) == 0 {
+		return nil, ErrEmptyShape
+	}
+
+	b.alignment = align
+	b.tokens = tokens
+
+	// 1. build a model for desired widths for lines, with indentation and line prefixes
+	b.shape = b.indentedShape(shape)
+	b.lineWidths = b.buildLengths(b.shape)
+
+	// 2. build a model that represent the tokens in terms of glue/box/penalty nodes,
+	// and compute a chained-list of break points, with successive passes
+	breakList := b.breakPasses(tokens)
 	if breakList == nil {
 		return nil, ErrCannotBeSet
 	}
 
-	// 4. render the final wrapped text
-	return l.render(breakList), nil
+	return breakList, nil
 }
 
-// buildUniformLengths fills 1 line of line length constraints,
-// making this constraint uniform across all lines.
-func (l *LineBreaker) buildUniformLengths(width float64) []float64 {
-	lengths := make([]float64, 1)
+// buildLengths fills the line length constraints from the shape of a paragraph.
+//
+// The last constraint applies to all subsequent lines.
+func (l *LineBreaker) buildLengths(shape Shape) []float64 {
+	lengths := make([]float64, len(shape))
 	for i := range lengths {
-		lengths[i] = l.scale(width)
+		lengths[i] = l.scale(shape[i].Width)
 	}
 
 	return lengths
@@ -128,71 +257,259 @@ func skipNodes(start int, nodes []nodeT) int {
 	return start
 }
 
-// render the nodes with the provided line breaks.
-//
-// Rendering is for now essentially for a plain terminal output, with fixed-width fonts.
-func (l *LineBreaker) render(breakList *breakPoint) []string {
-	var (
-		lines  []lineT
-		result []string
-	)
+// render the nodes with the provided line breaks, as strings.
+func (b *breaker) render(breakList *breakPoint) []string {
+	lines := b.lines(breakList)
+	result := make([]string, 0, len(lines))
+	attributesState := b.attrList.Iterator()
+
+	for i, line := range lines {
+		lineResult := new(strings.Builder)
+		b.renderLine(runesio.NewWriter(lineResult), i, line, attributesState)
+
+		result = append(result, lineResult.String())
+	}
+
+	return result
+}
+
+// renderRunes renders the nodes with the provided line breaks, as slices of runes.
+func (b *breaker) renderRunes(breakList *breakPoint) [][]rune {
+	lines := b.lines(breakList)
+	result := make([][]rune, 0, len(lines))
+	attributesState := b.attrList.Iterator()
+
+	for i, line := range lines {
+		lineResult := runesio.NewBuffer(len(line.nodes))
+		b.renderLine(lineResult, i, line, attributesState)
+
+		result = append(result, lineResult.Runes())
+	}
+
+	return result
+}
+
+// renderTo renders the nodes with the provided line breaks to a writer, with every line terminated by a new line.
+func (b *breaker) renderTo(w runesio.Writer, breakList *breakPoint) error {
+	lines := b.lines(breakList)
+	attributesState := b.attrList.Iterator()
+	lineResult := runesio.NewBuffer(0)
+
+	for i, line := range lines {
+		lineResult.Reset()
+		b.renderLine(lineResult, i, line, attributesState)
+		_, _ = lineResult.WriteRune('\n')
+
+		if _, err := w.WriteRunes(lineResult.Runes()); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// lines collects the nodes for every line, with the provided line breaks.
+func (b *breaker) lines(breakList *breakPoint) []lineT {
+	var lines []lineT
 
 	lineStart := 0
 	for brk := breakList.next; brk != nil; brk = brk.next {
-		lineStart = skipNodes(lineStart, l.nodes)
+		lineStart = skipNodes(lineStart, b.nodes)
 
 		lines = append(lines, lineT{
 			ratio:    brk.ratio,
-			nodes:    l.nodes[lineStart : brk.position+1],
+			nodes:    b.nodes[lineStart : brk.position+1],
 			position: brk.position,
+			line:     brk.line,
+			isLast:   brk.next == nil,
 		})
 
 		lineStart = brk.position
 	}
-	attributesState := l.attrList.Iterator() // TODO: First()
 
-	for _, line := range lines {
-		lineResult := new(strings.Builder)
-		runesWriter := runesio.AsRunesWriter(lineResult)
-		attributesState.StartOfLine(runesWriter)
-		for index, node := range line.nodes {
-			switch {
-			case node.isBox():
-				// render a box node
-				node.Render(runesWriter)
-				if node.HasRenderer() { // TODO: add state handling to box node
-					_ = attributesState.Next()
-				}
-
-			case node.isGlue():
-				// render a glue node
-				pad := node.width
-				if line.ratio < 0 {
-					pad += line.ratio * node.shrink
-				} else {
-					pad += line.ratio * node.stretch
-				}
-
-				spaces := repeatRunes(space, int(l.downScale(pad)))
-				_, _ = runesWriter.WriteRunes(spaces)
-
-			case node.isPenalty():
-				if l.renderHyphens && node.penalty == l.hyphenPenalty && index == len(line.nodes)-1 {
-					// render a soft hyphen node
-					_, _ = runesWriter.WriteRunes(hyphen)
-				}
+	return lines
+}
+
+// renderLine renders the nodes of a single line.
+//
+// Rendering is for now essentially for a plain terminal output, with fixed-width fonts.
+func (b *breaker) renderLine(w runesio.Writer, i int, line lineT, attributesState *attributes.StateIterator) {
+	indent, pads := b.pads(line)
+	indent += int(b.shape.Line(line.line).Indent)
+
+	if prefix := b.linePrefix(line.line); len(prefix) > 0 {
+		_, _ = w.WriteRunes(prefix)
+	}
+
+	if indent > 0 {
+		_, _ = w.WriteRunes(repeatRunes(space, indent))
+	}
+
+	if i > 0 {
+		// resume the attributes of the previous line. On the first line, there is nothing to resume.
+		attributesState.StartOfLine(w)
+	}
+
+	for index, node := range line.nodes {
+		switch {
+		case node.isBox():
+			// render a box node
+			node.Render(w)
+			if node.HasRenderer() { // TODO: add state handling to box node
+				_ = attributesState.Next()
+			}
+
+		case node.isGlue():
+			// render a glue node
+			spaces := repeatRunes(space, pads[index])
+			_, _ = w.WriteRunes(spaces)
+
+		case node.isPenalty():
+			if b.isRenderedHyphen(node) && index == len(line.nodes)-1 {
+				// render a soft hyphen node
+				_, _ = w.WriteRunes(hyphen)
 			}
 		}
-		attributesState.EndOfLine(runesWriter)
+	}
 
-		result = append(result, lineResult.String())
+	attributesState.EndOfLine(w)
+}
+
+// isRenderedHyphen tells if a penalty node should be rendered as a visible hyphen,
+// whenever a line is broken at this node.
+func (l *LineBreaker) isRenderedHyphen(node nodeT) bool {
+	return l.renderHyphens && node.isPenalty() && node.penalty == l.hyphenPenalty
+}
+
+// pads computes the number of spaces to render for each glue node in a line,
+// as well as the number of spaces to render before the line.
+func (b *breaker) pads(line lineT) (int, []int) {
+	switch b.alignment {
+	case AlignJustify:
+		return 0, b.justifiedPads(line)
+	case AlignCenter, AlignRight:
+		return b.indentedPads(line)
+	default:
+		// ragged right: spaces are not stretched
+		pads, _, _ := b.naturalPads(line)
+
+		return 0, pads
+	}
+}
+
+// naturalPads computes the number of spaces to render for each glue node in a line,
+// without any stretching or shrinking.
+//
+// It returns the pads, the total width of the line and the number of stretchable glue nodes with a width.
+func (b *breaker) naturalPads(line lineT) ([]int, int, int) {
+	pads := make([]int, len(line.nodes))
+	last := len(line.nodes) - 1
+
+	var content, glues int
+	for index, node := range line.nodes {
+		switch {
+		case node.isBox():
+			content += int(b.downScale(node.width))
+
+		case node.isGlue():
+			if index == last {
+				// the line is broken at this glue, which is not rendered
+				continue
+			}
+
+			pads[index] = int(b.downScale(node.width))
+			content += pads[index]
+			if pads[index] > 0 && node.stretch > 0 {
+				glues++
+			}
+
+		case index == last && b.isRenderedHyphen(node):
+			content += int(b.downScale(node.width))
+		}
+	}
+
+	return pads, content, glues
+}
+
+// justifiedPads distributes spaces across the glue nodes of a line, so the rendered line
+// exactly fills the desired width.
+//
+// Since spaces on a terminal come as an integral number of cells, the extra spaces that cannot
+// be evenly distributed are allocated to the leftmost glues.
+//
+// The last line of a paragraph is not stretched.
+func (b *breaker) justifiedPads(line lineT) []int {
+	pads, content, glues := b.naturalPads(line)
+	if line.isLast || glues == 0 {
+		return pads
+	}
+
+	extra := int(b.downScale(b.idealWidth(line.line))) - content
+	if extra <= 0 {
+		return pads
+	}
+
+	each, remainder := extra/glues, extra%glues
+	for index, node := range line.nodes[:len(line.nodes)-1] {
+		if pads[index] == 0 || node.stretch <= 0 {
+			continue
+		}
+
+		pads[index] += each
+		if remainder > 0 {
+			pads[index]++
+			remainder--
+		}
+	}
+
+	return pads
+}
+
+// indentedPads computes the leading padding for a centered or right-aligned line.
+//
+// Spaces between words are rendered with their natural width.
+func (b *breaker) indentedPads(line lineT) (int, []int) {
+	pads, content, _ := b.naturalPads(line)
+
+	extra := int(b.downScale(b.idealWidth(line.line))) - content
+	if extra <= 0 {
+		return 0, pads
+	}
+
+	if b.alignment == AlignCenter {
+		return extra / 2, pads
+	}
+
+	return extra, pads
+}
+
+// String representation of an Alignment.
+func (a Alignment) String() string {
+	switch a {
+	case AlignLeft:
+		return "left"
+	case AlignJustify:
+		return "justify"
+	case AlignCenter:
+		return "center"
+	case AlignRight:
+		return "right"
+	default:
+		return ""
+	}
+}
+
+func toRunes(tokens []string) [][]rune {
+	result := make([][]rune, 0, len(tokens))
+	for _, token := range tokens {
+		result = append(result, []rune(token))
 	}
 
 	return result
 }
 
 func repeatRunes(in []rune, times int) []rune {
-	if len(in) == 0 || times == 0 {
+	if len(in) == 0 || times <= 0 {
 		return []rune{}
 	}
 
@@ -208,130 +525,441 @@ func repeatRunes(in []rune, times int) []rune {
 //
 // Raw tokens are split into:
 // * Renderers with the appropriate start/end ANSI control sequence
+// * segments separated by no-break spaces, which are modeled as glues that cannot be broken
+// * parts separated by word joiners, with no break allowed between them
 // * word parts separated by punctuation marks and other separators (not hyphens)
-// * word parts at legit hyphenation breakpoints
-func (l *LineBreaker) boxNodes(token []rune) []nodeT {
+// * word parts at soft hyphens, or else at legit hyphenation breakpoints
+func (b *breaker) boxNodes(token []rune) []nodeT {
 	nodes := make([]nodeT, 0, 10)
 
 	for _, stripped := range ansi.StripToken(token) { // there may be several start/stop escape sequences: break them down
-		tokenState := newTokenState(stripped, l.attrList)
+		tokenState := newTokenState(stripped, b.attrList)
 
 		// this text has been stripped from start/stop escape sequences. The attribute renderer will remember the start/stop sequences.
 		// We don't necessarily need to create as many renderers, but we must keep track of the state
-		for _, strippedFromPunct := range l.punctuator(stripped.Text) { // split punctuation marks as well as separators such as "/", "|", "_"...
-			if punctuator.IsPunctuation(strippedFromPunct) {
-				tokenState.Start(strippedFromPunct)
-
-				// A punctuation mark, or similar separator (e.g. "/", "|", "&"...).
-				//
-				// NOTE(fredbi): nice to have - we might want to distinguish different rules depending on
-				// the punctuation mark. E.g. ";", "&", "." should probably deserve a special processing.
-				// For the moment, this package essentially supports rendering with fixed-width fonts on a terminal, so this is not really needed.
+		var start int
+		for i, r := range stripped.Text {
+			if !isNoBreakSpace(r) {
+				continue
+			}
+
+			nodes = append(nodes, b.joinedNodes(stripped.Text[start:i], tokenState)...)
+			nodes = append(trimBreaks(nodes), b.noBreakSpaceNodes(r)...)
+			start = i + 1
+		}
+		nodes = append(nodes, b.joinedNodes(stripped.Text[start:], tokenState)...)
+
+		// add stop to the last renderer
+		tokenState.Stop()
+	}
+
+	return nodes
+}
+
+// joinedNodes models the nodes for some text in which word joiners prevent any break.
+//
+// Every break point found at the end of a part, before a word joiner, is removed.
+func (b *breaker) joinedNodes(text []rune, tokenState *tokenState) []nodeT {
+	nodes := make([]nodeT, 0, 10)
+
+	var start int
+	for i, r := range text {
+		if r != WordJoiner {
+			continue
+		}
+
+		nodes = append(nodes, b.partNodes(text[start:i], tokenState)...)
+		nodes = trimBreaks(nodes)
+		start = i + 1
+	}
+
+	return append(nodes, b.partNodes(text[start:], tokenState)...)
+}
+
+// partNodes models the nodes for a part of a word, with possible breaks
+// after punctuation marks, explicit hyphens, soft hyphens and legit hyphenation breakpoints.
+func (b *breaker) partNodes(text []rune, tokenState *tokenState) []nodeT {
+	nodes := make([]nodeT, 0, 10)
+
+	parts := b.punctuator(text) // split punctuation marks as well as separators such as "/", "|", "_"...
+	for i, strippedFromPunct := range parts {
+		if punctuator.IsPunctuation(strippedFromPunct) {
+			tokenState.Start(strippedFromPunct)
+
+			// A punctuation mark, or similar separator (e.g. "/", "|", "&"...).
+			//
+			// NOTE(fredbi): nice to have - we might want to distinguish different rules depending on
+			// the punctuation mark. E.g. ";", "&", "." should probably deserve a special processing.
+			// For the moment, this package essentially supports rendering with fixed-width fonts on a terminal, so this is not really needed.
+			// No space before the punctuation mark. Some typographic rules disagree with this, e.g. for ";".
+			// The penalty for a break after the punctuation mark won't be mixed with hyphens.
+			nodes = append(nodes,
+				b.breakAfterBox(newBox(b.scale(b.measurer(strippedFromPunct)), strippedFromPunct, tokenState.Current()), b.punctuationPenalty)...,
+			)
+
+			continue
+		}
+
+		// a punctuation mark after this word cannot be broken from it, and must fit on the same line
+		trailing := b.stickyWidth(parts, i, punctuator.IsPunctuation)
+
+		if hasRune(strippedFromPunct, SoftHyphen) {
+			// Soft hyphens in the source are the only legit hyphenation points for this word.
+			// They are honored by all passes, like discretionary hyphens in TeX.
+			nodes = append(nodes, b.softHyphenNodes(strippedFromPunct, trailing, tokenState)...)
+
+			continue
+		}
+
+		if !b.pass.hyphenate || len(strippedFromPunct) <= b.minHyphenate {
+			// Either word breaking is forbidden or this token is too short for a legitimate hyphenation
+			nodes = append(nodes, b.wordBoxes(strippedFromPunct, trailing, tokenState)...)
+
+			continue
+		}
+
+		words := hyphenator.SplitWord(strippedFromPunct)
+		for j, word := range words { // split on explicit hyphens
+			// An explicit hyphen: this will be rendered as a regular token, but provides a legit line break point.
+			if hyphenator.IsHyphen(word) {
+				tokenState.Start(word)
+				// this penalty won't be mixed with soft hyphens
 				nodes = append(nodes,
-					newPenalty(noWidth, infinity, unflaggedPenalty),
-					newGlue(noWidth, l.glueStretch, noShrink), // no space before the punctuation mark. Some typographic rules disagree with this, e.g. for ";"
-					newBox(l.scale(l.measurer(strippedFromPunct)), strippedFromPunct, tokenState.Current()),
-					newPenalty(noWidth, l.punctuationPenalty, flaggedPenalty), // this penalty won't be mixed with hyphens
-					newGlue(noWidth, -l.glueStretch, noShrink),
+					b.breakAfterBox(newBox(b.scale(b.measurer(word)), word, tokenState.Current()), b.hardHyphenPenalty)...,
 				)
 
 				continue
 			}
 
-			if !l.wordBreak || len(strippedFromPunct) <= l.minHyphenate {
-				// Either word breaking is forbidden or this token is too short for a legitimate hyphenation
-				tokenState.Start(strippedFromPunct)
-				nodes = append(nodes, newBox(l.scale(l.measurer(strippedFromPunct)), strippedFromPunct, tokenState.Current()))
+			// Soft hyphens: the hyphenator returns word parts, broken at legit hyphenation breakpoints
+			hyphenated := b.hyphenator(word)
 
-				continue
+			// word break points are associated with a penalty
+			for _, part := range hyphenated[:len(hyphenated)-1] {
+				nodes = append(nodes, b.wordBoxes(part, b.renderedHyphenWidth(), tokenState)...)
+				nodes = append(nodes, b.pushHyphen()...)
 			}
 
-			for _, word := range hyphenator.SplitWord(strippedFromPunct) { // split on explicit hyphens
-				// An explicit hyphen: this will be rendered as a regular token, but provides a legit line break point.
-				if hyphenator.IsHyphen(word) {
-					tokenState.Start(word)
-					nodes = append(nodes,
-						newPenalty(noWidth, infinity, unflaggedPenalty),
-						newGlue(noWidth, l.glueStretch, noShrink),
-						newBox(l.scale(l.measurer(word)), word, tokenState.Current()),
-						newPenalty(noWidth, l.hardHyphenPenalty, flaggedPenalty), // this penalty won't be mixed with soft hyphens
-						newGlue(noWidth, -l.glueStretch, noShrink),
-					)
-
-					continue
-				}
-
-				// Soft hyphens: the hyphenator returns word parts, broken at legit hyphenation breakpoints
-				hyphenated := l.hyphenator(word)
-
-				// word break points are associated with a penalty
-				for _, part := range hyphenated[:len(hyphenated)-1] {
-					tokenState.Start(part)
-					nodes = append(nodes,
-						newBox(l.scale(l.measurer(part)), part, tokenState.Current()),
-					)
-					nodes = append(nodes, l.pushHyphen()...)
-				}
-
-				lastPart := hyphenated[len(hyphenated)-1]
-				tokenState.Start(lastPart)
-				nodes = append(nodes,
-					newBox(l.scale(l.measurer(lastPart)), lastPart, tokenState.Current()),
-				)
+			lastPart := hyphenated[len(hyphenated)-1]
+			lastTrailing := trailing
+			if j < len(words)-1 {
+				lastTrailing = b.stickyWidth(words, j, hyphenator.IsHyphen)
 			}
+			nodes = append(nodes, b.wordBoxes(lastPart, lastTrailing, tokenState)...)
 		}
-		// add stop to the last renderer
-		tokenState.Stop()
 	}
 
 	return nodes
 }
 
-func (l *LineBreaker) pushHyphen() []nodeT {
-	if l.renderHyphens {
+// softHyphenNodes yields the boxes for a word with soft hyphens, which are not rendered
+// unless the word is hyphenated there.
+//
+// The trailing width must fit on the same line as the end of the word.
+func (b *breaker) softHyphenNodes(word []rune, trailing float64, tokenState *tokenState) []nodeT {
+	nodes := make([]nodeT, 0, 10)
+
+	var start int
+	for i, r := range word {
+		if r != SoftHyphen {
+			continue
+		}
+
+		if i > start {
+			nodes = append(nodes, b.wordBoxes(word[start:i], b.renderedHyphenWidth(), tokenState)...)
+			nodes = append(nodes, b.pushHyphen()...)
+		}
+		start = i + 1
+	}
+
+	if start < len(word) {
+		return append(nodes, b.wordBoxes(word[start:], trailing, tokenState)...)
+	}
+
+	return trimBreaks(nodes) // a trailing soft hyphen doesn't break anything
+}
+
+// noBreakSpaceNodes yields the nodes for a no-break space: a glue which is not a legit break point.
+//
+// A no-break space stretches and shrinks like a regular space in justified text,
+// whereas a narrow no-break space always keeps its natural width.
+func (b *breaker) noBreakSpaceNodes(r rune) []nodeT {
+	glue := newGlue(b.spaceWidth, noStretch, noShrink)
+	if r == NoBreakSpace && b.alignment == AlignJustify {
+		glue = newGlue(b.spaceWidth, b.spaceStretch, b.spaceShrink)
+	}
+
+	return []nodeT{
+		newPenalty(noWidth, infinity, unflaggedPenalty),
+		glue,
+	}
+}
+
+// trimBreaks removes the nodes following the last box with some content,
+// so that no break may occur at the end of these nodes.
+func trimBreaks(nodes []nodeT) []nodeT {
+	for i := len(nodes) - 1; i >= 0; i-- {
+		if nodes[i].isBox() && len(nodes[i].value) > 0 {
+			return nodes[:i+1]
+		}
+	}
+
+	return nodes[:0]
+}
+
+// wordBoxes yields the box node for a word, or a part of a word.
+//
+// In the final pass, words that are too wide to fit on the narrowest line may be
+// forcibly broken at any cell boundary, if WithForceBreak is enabled.
+// The trailing width (e.g. a hyphen or a punctuation mark) must fit on the same line as the end of the word.
+// When even the last cell of the word cannot fit with it, the trailing part is forcibly broken from the word.
+func (b *breaker) wordBoxes(word []rune, trailing float64, tokenState *tokenState) []nodeT {
+	width := b.scale(b.measurer(word))
+	if !b.pass.final || !b.forceBreak || width+trailing <= b.minLineWidth() || len(word) == 0 {
+		tokenState.Start(word)
+
+		return []nodeT{newBox(width, word, tokenState.Current())}
+	}
+
+	nodes := make([]nodeT, 0, 5*len(word))
+	for i := range word[:len(word)-1] {
+		cell := word[i : i+1]
+		tokenState.Start(cell)
+		nodes = append(nodes,
+			b.breakAfterBox(newBox(b.scale(b.measurer(cell)), cell, tokenState.Current()), b.forceBreakPenalty)...,
+		)
+	}
+
+	cell := word[len(word)-1:]
+	tokenState.Start(cell)
+	last := newBox(b.scale(b.measurer(cell)), cell, tokenState.Current())
+	if trailing == 0 || last.width+trailing <= b.minLineWidth() {
+		return append(nodes, last)
+	}
+
+	return append(nodes, b.breakAfterBox(last, b.forceBreakPenalty)...)
+}
+
+// stickyWidth yields the width of the part following parts[i], whenever no break may occur before it.
+func (b *breaker) stickyWidth(parts [][]rune, i int, isSticky func([]rune) bool) float64 {
+	if i+1 >= len(parts) || !isSticky(parts[i+1]) {
+		return 0
+	}
+
+	return b.scale(b.measurer(parts[i+1]))
+}
+
+// renderedHyphenWidth yields the width of the hyphen rendered when a word is hyphenated.
+func (b *breaker) renderedHyphenWidth() float64 {
+	if !b.renderHyphens {
+		return noWidth
+	}
+
+	return b.hyphenWidth
+}
+
+// breakAfterBox yields a box node followed by a legit (flagged) break point with some penalty.
+func (b *breaker) breakAfterBox(box nodeT, penalty float64) []nodeT {
+	switch b.alignment {
+	case AlignJustify:
+		return []nodeT{
+			box,
+			newPenalty(noWidth, penalty, flaggedPenalty),
+		}
+	case AlignCenter:
+		return append([]nodeT{box}, b.centeredBreak(noWidth, noWidth, penalty, flaggedPenalty)...)
+	case AlignRight:
+		return append([]nodeT{box}, b.raggedLeftBreak(noWidth, noWidth, penalty, flaggedPenalty)...)
+	}
+
+	// ragged right
+	return []nodeT{
+		newPenalty(noWidth, infinity, unflaggedPenalty),
+		newGlue(noWidth, b.glueStretch, noShrink),
+		box,
+		newPenalty(noWidth, penalty, flaggedPenalty),
+		newGlue(noWidth, -b.glueStretch, noShrink),
+	}
+}
+
+// centeredBreak yields the glue/penalty/glue sandwich that models a legit break point in centered text.
+//
+// When the break is not taken, the stretchability of the glues cancels out and the glue width
+// (e.g. a space) is rendered. When the break is taken, both the end of the current line
+// and the start of the next one may stretch, since the empty box prevents the
+// next glue from being discarded after the break.
+func (b *breaker) centeredBreak(glueWidth, penaltyWidth, penalty float64, flagged bool) []nodeT {
+	stretch := b.glueStretch / 2
+
+	return []nodeT{
+		newPenalty(noWidth, infinity, unflaggedPenalty),
+		newGlue(noWidth, stretch, noShrink),
+		newPenalty(penaltyWidth, penalty, flagged),
+		newGlue(glueWidth, -2*stretch, noShrink),
+		newBox(noWidth, nil, nil),
+		newPenalty(noWidth, infinity, unflaggedPenalty),
+		newGlue(noWidth, stretch, noShrink),
+	}
+}
+
+// raggedLeftBreak yields the nodes that model a legit break point in right-aligned text,
+// mirroring the recipe for ragged right text.
+//
+// When the break is not taken, the stretchability of the glues cancels out and the glue width
+// (e.g. a space) is rendered. When the break is taken, only the start of the next line may stretch,
+// since the empty box prevents the next glue from being discarded after the break.
+func (b *breaker) raggedLeftBreak(glueWidth, penaltyWidth, penalty float64, flagged bool) []nodeT {
+	return []nodeT{
+		newPenalty(penaltyWidth, penalty, flagged),
+		newGlue(glueWidth, -b.glueStretch, noShrink),
+		newBox(noWidth, nil, nil),
+		newPenalty(noWidth, infinity, unflaggedPenalty),
+		newGlue(noWidth, b.glueStretch, noShrink),
+	}
+}
+
+func (b *breaker) pushHyphen() []nodeT {
+	switch b.alignment {
+	case AlignCenter:
+		return b.centeredBreak(noWidth, b.renderedHyphenWidth(), b.hyphenPenalty, flaggedPenalty)
+	case AlignRight:
+		return b.raggedLeftBreak(noWidth, b.renderedHyphenWidth(), b.hyphenPenalty, flaggedPenalty)
+	}
+
+	if b.renderHyphens && b.alignment == AlignJustify {
+		// when rendering hyphens, the penalty incurs some consumed width
+		return []nodeT{
+			newPenalty(b.hyphenWidth, b.hyphenPenalty, flaggedPenalty),
+		}
+	}
+
+	if b.renderHyphens {
 		// when rendering hyphens, the penalty incurs some consumed width
 		return []nodeT{
-			// justified:
-			// newPenalty(l.hyphenWidth, l.hyphenPenalty, flaggedPenalty),
 			// ragged right:
 			newPenalty(noWidth, infinity, unflaggedPenalty),
-			newGlue(noWidth, l.glueStretch, noShrink),
-			newPenalty(l.hyphenWidth, l.hyphenPenalty, flaggedPenalty),
-			newGlue(noWidth, -l.glueStretch, noShrink),
+			newGlue(noWidth, b.glueStretch, noShrink),
+			newPenalty(b.hyphenWidth, b.hyphenPenalty, flaggedPenalty),
+			newGlue(noWidth, -b.glueStretch, noShrink),
 		}
 	}
 
 	return []nodeT{
 		// when hyphens are not rendered (words are just broken), there is no width associated to the penalty
-		newPenalty(noWidth, l.hyphenPenalty, flaggedPenalty),
+		newPenalty(noWidth, b.hyphenPenalty, flaggedPenalty),
+	}
+}
+
+// wordNodes prepares the nodes for a word token, keeping track of the index of this token,
+// and of the offset of every box in this token.
+func (b *breaker) wordNodes(token int, word []rune) []nodeT {
+	nodes := b.boxNodes(word)
+	offsets := textOffsets(word)
+	var cursor int // boxes cover the text of the token, in order
+
+	for i := range nodes {
+		nodes[i].token = token
+
+		if !nodes[i].isBox() || cursor >= len(offsets) {
+			continue
+		}
+
+		nodes[i].offset = offsets[cursor]
+		cursor += len(nodes[i].value)
 	}
+
+	return nodes
 }
 
-// leftAlignedNodes prepares nodes for left-aligned rendering (ragged right).
-func (l *LineBreaker) leftAlignedNodes(tokens []string) []nodeT {
+// buildNodes prepares nodes according to the desired alignment.
+func (b *breaker) buildNodes(tokens [][]rune) []nodeT {
 	if len(tokens) == 0 {
 		return nil
 	}
 
-	nodes := make([]nodeT, 0, 4*(len(tokens)-1)+3)
+	nodes := make([]nodeT, 0, 8*len(tokens))
+	nodes = append(nodes, b.openingNodes()...)
 
 	// transform tokens into a list of nodes of type (box|glue|penalty)
-	for _, word := range tokens[:len(tokens)-1] {
-		nodes = append(nodes, l.boxNodes([]rune(word))...) // a word token, possibly broken in parts
-		// from K&P: justified:
-		// nodes = append(nodes, newGlue(l.spaceWidth, l.glueStretch, l.glueShrink))
-		// from K&P: ragged right:
-		nodes = append(nodes, newGlue(noWidth, l.glueStretch, noShrink))
-		nodes = append(nodes, newPenalty(noWidth, 0, unflaggedPenalty))
-		nodes = append(nodes, newGlue(l.spaceWidth, -l.glueStretch, noShrink))
+	for i, word := range tokens {
+		nodes = append(nodes, b.tokenNodes(i, word, i == len(tokens)-1)...)
 	}
 
-	// last token: complete the list of nodes with a final infinite glue and penalty.
-	nodes = append(nodes, l.boxNodes([]rune(tokens[len(tokens)-1]))...)
-	nodes = append(nodes, newGlue(noWidth, infinity, noShrink))
-	nodes = append(nodes, newPenalty(noWidth, -infinity, flaggedPenalty))
-
 	return nodes
 }
 
-// TODO: center(), justify()?
+// tokenNodes prepares the nodes for a word token, followed by the space after this token,
+// or by the end of the paragraph for the last token.
+func (b *breaker) tokenNodes(token int, word []rune, isLast bool) []nodeT {
+	nodes := b.wordNodes(token, word) // a word token, possibly broken in parts
+	if isLast {
+		return append(nodes, b.closingNodes()...)
+	}
+
+	return append(nodes, b.spaceNodes()...)
+}
+
+// openingNodes yields the nodes starting a paragraph.
+func (b *breaker) openingNodes() []nodeT {
+	switch b.alignment {
+	case AlignCenter:
+		// the first line may stretch on the left
+		return []nodeT{
+			newBox(noWidth, nil, nil),
+			newPenalty(noWidth, infinity, unflaggedPenalty),
+			newGlue(noWidth, b.glueStretch/2, noShrink),
+		}
+	case AlignRight:
+		// the first line stretches on the left only
+		return []nodeT{
+			newBox(noWidth, nil, nil),
+			newPenalty(noWidth, infinity, unflaggedPenalty),
+			newGlue(noWidth, b.glueStretch, noShrink),
+		}
+	default:
+		return nil
+	}
+}
+
+// spaceNodes yields the nodes for a space between words.
+func (b *breaker) spaceNodes() []nodeT {
+	switch b.alignment {
+	case AlignJustify:
+		// spaces between words are modeled as glues with some stretchability
+		return []nodeT{
+			newGlue(b.spaceWidth, b.spaceStretch, b.spaceShrink),
+		}
+	case AlignCenter:
+		// every space between words is surrounded by a glue/penalty/glue sandwich, so that
+		// the stretchability of a line is found on both ends of the line.
+		return b.centeredBreak(b.spaceWidth, noWidth, 0, unflaggedPenalty)
+	case AlignRight:
+		// ragged left: the stretchability of a line is found at the start of the line
+		return b.raggedLeftBreak(b.spaceWidth, noWidth, 0, unflaggedPenalty)
+	default:
+		// from K&P: ragged right
+		return []nodeT{
+			newGlue(noWidth, b.glueStretch, noShrink),
+			newPenalty(noWidth, 0, unflaggedPenalty),
+			newGlue(b.spaceWidth, -b.glueStretch, noShrink),
+		}
+	}
+}
+
+// closingNodes yields the nodes ending a paragraph.
+func (b *breaker) closingNodes() []nodeT {
+	switch b.alignment {
+	case AlignCenter:
+		// complete the list of nodes with a final glue and a forced break
+		return []nodeT{
+			newGlue(noWidth, b.glueStretch/2, noShrink),
+			newPenalty(noWidth, -infinity, flaggedPenalty),
+		}
+	default:
+		// complete the list of nodes with a final infinite glue and penalty
+		return []nodeT{
+			newGlue(noWidth, infinity, noShrink),
+			newPenalty(noWidth, -infinity, flaggedPenalty),
+		}
+	}
+}
@@ -8,6 +8,7 @@ import (
 
 	"github.com/fredbi/go-typeset/terminal/ansi"
 	"github.com/fredbi/go-typeset/terminal/runes"
+	"github.com/fredbi/go-typeset/terminal/runes/runesio"
 	"github.com/fredbi/go-typeset/wordbreak/hyphenator"
 	"github.com/stretchr/testify/require"
 )
@@ -182,6 +183,514 @@ func TestLineBreaker(t *testing.T) {
 	})
 }
 
+func TestJustify(t *testing.T) {
+	const lorem = `Lorem ipsum dolor sit amet, consectetur adipiscing elit, ` +
+		`sed do eiusmod tempor incididunt ut labore et dolore magna aliqua. ` +
+		`Ut enim ad minim veniam, quis nostrud exercitation ullamco laboris nisi ut aliquip ex ea commodo consequat.`
+
+	t.Run("should justify a paragraph (width 50)", testJustify(lorem, 50, WithWordBreak(false)))
+	t.Run("should justify Knuth's classical example (width 30)", testJustify(grimm, 30, WithWordBreak(false)))
+	t.Run("should justify Knuth's classical example with hyphens (width 20)", testJustify(grimm, 20))
+	t.Run("should justify Knuth's classical example with hyphens (width 25)", testJustify(grimm, 25))
+
+	t.Run("should render a single short line unchanged", func(t *testing.T) {
+		lb := New()
+		lines, err := lb.Justify(strings.Fields("a short line"), 30)
+		require.NoError(t, err)
+		require.Equal(t, []string{"a short line"}, lines)
+	})
+
+	t.Run("should set lines which cannot stretch, like left-aligned text", func(t *testing.T) {
+		const url = `See https://www.unicode.org/Public/15.0.0/ucd/emoji/emoji-data.txt for details.`
+
+		for _, example := range []struct {
+			Paragraph string
+			Display   float64
+			Opts      []Option
+		}{
+			{Paragraph: "internationalization is long", Display: 22},
+			{Paragraph: lorem, Display: 12},
+			{Paragraph: lorem, Display: 20, Opts: []Option{WithWordBreak(false)}},
+			{Paragraph: url, Display: 12},
+			{Paragraph: url, Display: 20},
+		} {
+			tokens := strings.Fields(example.Paragraph)
+
+			_, err := New(example.Opts...).LeftAlignUniform(tokens, example.Display)
+			require.NoError(t, err)
+
+			lines, err := New(example.Opts...).Justify(tokens, example.Display)
+			require.NoErrorf(t, err, "expected %q to be justified at width %v", example.Paragraph, example.Display)
+			testRenderLines(lines, example.Display)
+
+			for _, line := range lines {
+				width := runes.Widths([]rune(line))
+				require.LessOrEqualf(t, width, int(example.Display), "expected line %q to fit", line)
+
+				if width < int(example.Display) {
+					require.NotContainsf(t, line, "  ",
+						"expected underfull line %q to be left-aligned", line,
+					)
+				}
+			}
+		}
+	})
+
+	t.Run("should stretch spaces twice their width", func(t *testing.T) {
+		lb := New()
+		require.Equal(t, 2*lb.spaceWidth, lb.spaceStretch)
+	})
+}
+
+func testJustify(paragraph string, display float64, opts ...Option) func(*testing.T) {
+	return func(t *testing.T) {
+		tokens := strings.Fields(paragraph)
+
+		lb := New(opts...)
+		lines, err := lb.Justify(tokens, display)
+		require.NoError(t, err)
+		require.NotEmpty(t, lines)
+
+		testRenderLines(lines, display)
+
+		for _, line := range lines[:len(lines)-1] {
+			require.Equalf(t, int(display), runes.Widths([]rune(line)),
+				"expected line %q to be justified", line,
+			)
+		}
+
+		last := lines[len(lines)-1]
+		require.LessOrEqual(t, runes.Widths([]rune(last)), int(display))
+		require.NotContainsf(t, last, "  ",
+			"expected last line %q to be left-aligned", last,
+		)
+	}
+}
+
+func TestCenter(t *testing.T) {
+	t.Run("should center Knuth's classical example (width 30)", testAligned(grimm, 30, AlignCenter, WithWordBreak(false)))
+	t.Run("should center Knuth's classical example with hyphens (width 20)", testAligned(grimm, 20, AlignCenter))
+
+	t.Run("should center a single short line", func(t *testing.T) {
+		lb := New()
+		lines, err := lb.Center(strings.Fields("a short line"), 20)
+		require.NoError(t, err)
+		require.Equal(t, []string{"    a short line"}, lines)
+	})
+}
+
+func TestRightAlign(t *testing.T) {
+	t.Run("should right-align Knuth's classical example (width 30)", testAligned(grimm, 30, AlignRight, WithWordBreak(false)))
+	t.Run("should right-align Knuth's classical example with hyphens (width 20)", testAligned(grimm, 20, AlignRight))
+
+	t.Run("should right-align a single short line", func(t *testing.T) {
+		lb := New()
+		lines, err := lb.RightAlign(strings.Fields("a short line"), 20)
+		require.NoError(t, err)
+		require.Equal(t, []string{"        a short line"}, lines)
+	})
+
+	t.Run("should break lines like left-aligned text", func(t *testing.T) {
+		const lorem = `Lorem ipsum dolor sit amet, consectetur adipiscing elit, ` +
+			`sed do eiusmod tempor incididunt ut labore et dolore magna aliqua.`
+
+		for _, paragraph := range []string{grimm, lorem} {
+			tokens := strings.Fields(paragraph)
+
+			for display := 12.0; display <= 50; display++ {
+				for _, opts := range [][]Option{nil, {WithWordBreak(false)}} {
+					lb := New(opts...)
+
+					left, err := lb.LeftAlignUniform(tokens, display)
+					require.NoError(t, err)
+
+					right, err := lb.RightAlign(tokens, display)
+					require.NoError(t, err)
+					require.Len(t, right, len(left))
+
+					for i := range left {
+						require.Equalf(t, strings.TrimRight(left[i], " "), strings.TrimLeft(right[i], " "),
+							"expected the same breaks as left-aligned text (width: %v)", display,
+						)
+					}
+				}
+			}
+		}
+	})
+}
+
+func testAligned(paragraph string, display float64, align Alignment, opts ...Option) func(*testing.T) {
+	return func(t *testing.T) {
+		tokens := strings.Fields(paragraph)
+
+		lb := New(opts...)
+		var (
+			lines []string
+			err   error
+		)
+		if align == AlignCenter {
+			lines, err = lb.Center(tokens, display)
+		} else {
+			lines, err = lb.RightAlign(tokens, display)
+		}
+		require.NoError(t, err)
+		require.NotEmpty(t, lines)
+
+		testRenderLines(lines, display)
+
+		for _, line := range lines {
+			trimmed := strings.TrimLeft(line, " ")
+			require.NotContainsf(t, trimmed, "  ",
+				"expected words in line %q to be separated by a single space", line,
+			)
+
+			width := runes.Widths([]rune(trimmed))
+			require.LessOrEqual(t, width, int(display))
+
+			indent := len(line) - len(trimmed)
+			if align == AlignCenter {
+				require.Equalf(t, (int(display)-width)/2, indent,
+					"expected line %q to be centered", line,
+				)
+
+				continue
+			}
+
+			require.Equalf(t, int(display), indent+width,
+				"expected line %q to be right-aligned", line,
+			)
+		}
+	}
+}
+
+func TestLooseness(t *testing.T) {
+	const display = 30.0
+	tokens := strings.Fields(grimm)
+
+	optimal, err := New(WithWordBreak(false)).LeftAlignUniform(tokens, display)
+	require.NoError(t, err)
+
+	t.Run("should render a looser paragraph", func(t *testing.T) {
+		lines, err := New(WithWordBreak(false), WithLooseness(1)).LeftAlignUniform(tokens, display)
+		require.NoError(t, err)
+
+		testRenderLines(lines, display)
+		require.Len(t, lines, len(optimal)+1)
+	})
+
+	t.Run("should render a tighter paragraph", func(t *testing.T) {
+		const narrow = 20.0
+
+		tight, err := New().LeftAlignUniform(tokens, narrow)
+		require.NoError(t, err)
+
+		lines, err := New(WithLooseness(-1)).LeftAlignUniform(tokens, narrow)
+		require.NoError(t, err)
+
+		testRenderLines(lines, narrow)
+		require.Len(t, lines, len(tight)-1)
+	})
+
+	t.Run("should fall back to the closest feasible number of lines", func(t *testing.T) {
+		lines, err := New(WithWordBreak(false), WithLooseness(-100)).LeftAlignUniform(tokens, display)
+		require.NoError(t, err)
+
+		testRenderLines(lines, display)
+		require.Len(t, lines, len(optimal))
+
+		lines, err = New(WithWordBreak(false), WithLooseness(100)).LeftAlignUniform(tokens, display)
+		require.NoError(t, err)
+
+		require.Greater(t, len(lines), len(optimal))
+	})
+}
+
+func TestShaped(t *testing.T) {
+	tokens := strings.Fields(grimm)
+
+	t.Run("should render a hanging indent", func(t *testing.T) {
+		shape := Shape{{Width: 30}, {Indent: 4, Width: 26}}
+
+		lines, err := New().Shaped(tokens, AlignLeft, shape)
+		require.NoError(t, err)
+		testRenderLines(lines, 30)
+
+		require.False(t, strings.HasPrefix(lines[0], " "))
+		for i, line := range lines {
+			require.LessOrEqual(t, runes.Widths([]rune(line)), 30)
+			if i == 0 {
+				continue
+			}
+
+			require.Truef(t, strings.HasPrefix(line, "    ") && line[4] != ' ',
+				"expected line %q to be indented by 4 cells", line,
+			)
+		}
+	})
+
+	t.Run("should justify lines of varying widths", func(t *testing.T) {
+		shape := ShapeFromWidths([]float64{20, 20, 30})
+
+		lines, err := New().Shaped(tokens, AlignJustify, shape)
+		require.NoError(t, err)
+		testRenderLines(lines, 30)
+
+		for i, line := range lines[:len(lines)-1] {
+			require.Equalf(t, 30, runes.Widths([]rune(line)),
+				"expected line %q to be justified", line,
+			)
+
+			if i < 2 {
+				require.Truef(t, strings.HasPrefix(line, strings.Repeat(" ", 10)),
+					"expected line %q to be indented by 10 cells", line,
+				)
+			}
+		}
+	})
+
+	t.Run("should build a shape from a function", func(t *testing.T) {
+		shape := ShapeFromFunc(3, func(line int) LineShape {
+			if line == 1 {
+				return LineShape{Width: 24}
+			}
+
+			return LineShape{Indent: 2, Width: 22}
+		})
+		require.Equal(t, LineShape{Width: 24}, shape.Line(1))
+		require.Equal(t, LineShape{Indent: 2, Width: 22}, shape.Line(3))
+		require.Equal(t, LineShape{Indent: 2, Width: 22}, shape.Line(10))
+
+		lines, err := New().Shaped(tokens, AlignRight, shape)
+		require.NoError(t, err)
+		testRenderLines(lines, 24)
+
+		for _, line := range lines {
+			require.Equalf(t, 24, runes.Widths([]rune(line)),
+				"expected line %q to be right-aligned", line,
+			)
+		}
+	})
+
+	t.Run("should not accept an empty shape", func(t *testing.T) {
+		_, err := New().Shaped(tokens, AlignLeft, nil)
+		require.ErrorIs(t, err, ErrEmptyShape)
+	})
+}
+
+func TestIndentation(t *testing.T) {
+	tokens := strings.Fields(grimm)
+
+	t.Run("should indent the first line", func(t *testing.T) {
+		lines, err := New(WithFirstLineIndent(4)).Shaped(tokens, AlignJustify, UniformShape(30))
+		require.NoError(t, err)
+		testRenderLines(lines, 30)
+
+		require.True(t, strings.HasPrefix(lines[0], "    ") && lines[0][4] != ' ')
+		for _, line := range lines[:len(lines)-1] {
+			require.Equalf(t, 30, runes.Widths([]rune(line)),
+				"expected line %q to be justified", line,
+			)
+		}
+
+		for _, line := range lines[1:] {
+			require.False(t, strings.HasPrefix(line, " "))
+		}
+	})
+
+	t.Run("should render a list item with a hanging indent", func(t *testing.T) {
+		lines, err := New(WithFirstLinePrefix("- "), WithHangingIndent(2)).Shaped(tokens, AlignLeft, UniformShape(30))
+		require.NoError(t, err)
+		testRenderLines(lines, 30)
+
+		require.True(t, strings.HasPrefix(lines[0], "- ") && lines[0][2] != ' ')
+		for _, line := range lines {
+			require.LessOrEqual(t, runes.Widths([]rune(line)), 30)
+		}
+
+		for _, line := range lines[1:] {
+			require.Truef(t, strings.HasPrefix(line, "  ") && line[2] != ' ',
+				"expected line %q to be indented by 2 cells", line,
+			)
+		}
+	})
+
+	t.Run("should render a prefix on every line", func(t *testing.T) {
+		const comment = "\033[2m// \033[0m"
+
+		for _, align := range []Alignment{AlignLeft, AlignJustify, AlignCenter, AlignRight} {
+			lines, err := New(WithPrefix(comment)).Shaped(tokens, align, UniformShape(30))
+			require.NoError(t, err)
+			testRenderLines(lines, 30)
+
+			for _, line := range lines {
+				require.True(t, strings.HasPrefix(line, comment))
+				require.LessOrEqual(t, runes.Widths(ansi.Strip([]rune(line))), 30)
+			}
+		}
+	})
+
+	t.Run("should map source positions after the prefix", func(t *testing.T) {
+		paragraph, err := New(WithPrefix("> "), WithFirstLineIndent(2)).Break(tokens, AlignLeft, UniformShape(30))
+		require.NoError(t, err)
+		require.Equal(t, 26.0, paragraph.Lines[0].Width)
+		require.Equal(t, 28.0, paragraph.Lines[1].Width)
+
+		line, column, ok := paragraph.Position(SourcePosition{Token: 0})
+		require.True(t, ok)
+		require.Equal(t, 1, line)
+		require.Equal(t, 4, column)
+		require.Equal(t, "> ", paragraph.Lines[1].String()[:2])
+
+		pos, ok := paragraph.Lines[1].SourceAt(2)
+		require.True(t, ok)
+		require.Equal(t, paragraph.Lines[1].Source.Start, pos)
+	})
+}
+
+func TestPasses(t *testing.T) {
+	const hash = `sha256:9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08`
+	tokens := strings.Fields(`the image digest is ` + hash + ` as computed`)
+
+	t.Run("should run the regular pass by default", func(t *testing.T) {
+		paragraph, err := New().Break(strings.Fields(grimm), AlignLeft, UniformShape(30))
+		require.NoError(t, err)
+		require.Equal(t, PassRegular, paragraph.Pass)
+		require.Empty(t, paragraph.Overfull)
+	})
+
+	t.Run("should succeed with a first pass without hyphenation", func(t *testing.T) {
+		paragraph, err := New(WithPretolerance(8)).Break(strings.Fields(grimm), AlignLeft, UniformShape(30))
+		require.NoError(t, err)
+		testRenderLines(paragraph.Strings(), 30)
+		require.Equal(t, PassNoHyphenation, paragraph.Pass)
+
+		for _, line := range paragraph.Strings() {
+			require.Falsef(t, strings.HasSuffix(line, "-") && !strings.HasSuffix(line, "lime-"),
+				"expected line %q not to be hyphenated", line,
+			)
+		}
+	})
+
+	t.Run("should fall back to the regular pass with hyphenation", func(t *testing.T) {
+		paragraph, err := New(WithPretolerance(0.1)).Break(strings.Fields(grimm), AlignLeft, UniformShape(20))
+		require.NoError(t, err)
+		require.Equal(t, PassRegular, paragraph.Pass)
+	})
+
+	t.Run("should succeed with an emergency pass", func(t *testing.T) {
+		_, err := New(WithTolerance(0.5)).LeftAlignUniform(strings.Fields(grimm), 12)
+		require.ErrorIs(t, err, ErrCannotBeSet)
+
+		paragraph, err := New(WithTolerance(0.5), WithEmergencyStretch(6)).Break(strings.Fields(grimm), AlignLeft, UniformShape(12))
+		require.NoError(t, err)
+		testRenderLines(paragraph.Strings(), 12)
+		require.Equal(t, PassEmergency, paragraph.Pass)
+		require.Empty(t, paragraph.Overfull)
+	})
+
+	t.Run("with a word which is too long to fit", func(t *testing.T) {
+		const display = 30.0
+
+		t.Run("should not set the paragraph by default", func(t *testing.T) {
+			_, err := New().LeftAlignUniform(tokens, display)
+			require.ErrorIs(t, err, ErrCannotBeSet)
+		})
+
+		t.Run("should render an overfull line", func(t *testing.T) {
+			paragraph, err := New(WithOverfull(true)).Break(tokens, AlignLeft, UniformShape(display))
+			require.NoError(t, err)
+			lines := paragraph.Strings()
+			testRenderLines(lines, display)
+
+			require.Equal(t, PassOverfull, paragraph.Pass)
+			require.Len(t, paragraph.Overfull, 1)
+
+			overfull := lines[paragraph.Overfull[0]-1]
+			require.Contains(t, overfull, strings.TrimPrefix(hash, "sha256:"))
+			require.Greater(t, runes.Widths([]rune(overfull)), int(display))
+		})
+
+		t.Run("should force a break inside the word", func(t *testing.T) {
+			for _, align := range []Alignment{AlignLeft, AlignJustify, AlignCenter, AlignRight} {
+				paragraph, err := New(WithForceBreak(true)).Break(tokens, align, UniformShape(display))
+				require.NoError(t, err)
+				lines := paragraph.Strings()
+				testRenderLines(lines, display)
+
+				require.Equal(t, PassOverfull, paragraph.Pass)
+				require.Empty(t, paragraph.Overfull)
+
+				for _, line := range lines {
+					require.LessOrEqualf(t, runes.Widths([]rune(line)), int(display),
+						"expected line %q to fit (alignment: %v)", line, align,
+					)
+				}
+
+				trimmed := make([]string, 0, len(lines))
+				for _, line := range lines {
+					trimmed = append(trimmed, strings.TrimSpace(line))
+				}
+				require.Contains(t, strings.Join(trimmed, ""), hash)
+			}
+		})
+
+		t.Run("should prefer an underfull line to an overfull one", func(t *testing.T) {
+			tokens := strings.Fields(`internationalization is long ` + hash)
+
+			for _, align := range []Alignment{AlignLeft, AlignJustify, AlignCenter, AlignRight} {
+				paragraph, err := New(WithOverfull(true), WithWordBreak(false)).Break(tokens, align, UniformShape(22))
+				require.NoError(t, err)
+				testRenderLines(paragraph.Strings(), 22)
+				require.Equal(t, PassOverfull, paragraph.Pass)
+
+				require.Equal(t, "internationalization", strings.TrimSpace(paragraph.Lines[0].String()))
+				digest := strings.TrimPrefix(hash, "sha256:")
+				for _, line := range paragraph.Lines {
+					require.Equalf(t, strings.Contains(line.String(), digest), line.Overfull(),
+						"expected only the line with %q to be overfull (alignment: %v)", digest, align,
+					)
+				}
+			}
+		})
+	})
+
+	t.Run("should not render a trailing empty line", func(t *testing.T) {
+		for _, align := range []Alignment{AlignLeft, AlignJustify, AlignCenter, AlignRight} {
+			lines, err := New(WithOverfull(true), WithWordBreak(false)).Shaped([]string{"Description"}, align, UniformShape(10))
+			require.NoError(t, err)
+			require.Equal(t, []string{"Description"}, lines)
+		}
+	})
+
+	t.Run("should fit every line with forced breaks", func(t *testing.T) {
+		for _, paragraph := range []string{
+			grimm,
+			"e q",
+			"Description",
+			`See https://www.unicode.org/Public/15.0.0/ucd/emoji/emoji-data.txt for details.`,
+			`the image digest is ` + hash + ` (as computed) under an old lime-tree.`,
+		} {
+			tokens := strings.Fields(paragraph)
+
+			for display := 1.0; display <= 30; display++ {
+				for _, align := range []Alignment{AlignLeft, AlignJustify, AlignCenter, AlignRight} {
+					lines, err := New(WithForceBreak(true)).Shaped(tokens, align, UniformShape(display))
+					require.NoError(t, err)
+
+					for _, line := range lines {
+						require.LessOrEqualf(t, runes.Widths([]rune(line)), int(display),
+							"expected line %q to fit (width: %v, alignment: %v)", line, display, align,
+						)
+						require.NotEmptyf(t, strings.TrimSpace(line),
+							"expected line to hold some content (width: %v, alignment: %v)", display, align,
+						)
+					}
+				}
+			}
+		}
+	})
+}
+
 //nolint:unparam
 func testLeftAlign(paragraph string, display float64, opts ...Option) func(*testing.T) {
 	return func(t *testing.T) {
@@ -223,3 +732,46 @@ func testLeftAlignHyphenize(paragraph string, display float64, opts ...Option) f
 		testRenderLines(lines, display)
 	}
 }
+
+func TestRunes(t *testing.T) {
+	tokens := strings.Fields(grimm)
+	runeTokens := make([][]rune, 0, len(tokens))
+	for _, token := range tokens {
+		runeTokens = append(runeTokens, []rune(token))
+	}
+
+	for _, align := range []Alignment{AlignLeft, AlignJustify, AlignCenter, AlignRight} {
+		align := align
+
+		t.Run(fmt.Sprintf("should render runes like strings (%v)", align), func(t *testing.T) {
+			shape := UniformShape(30)
+			expected, err := New().Shaped(tokens, align, shape)
+			require.NoError(t, err)
+
+			lines, err := New().ShapedRunes(runeTokens, align, shape)
+			require.NoError(t, err)
+			require.Len(t, lines, len(expected))
+
+			for i, line := range lines {
+				require.Equal(t, expected[i], string(line))
+			}
+		})
+	}
+
+	t.Run("should write lines to a runes writer", func(t *testing.T) {
+		shape := UniformShape(25)
+		expected, err := New().Shaped(tokens, AlignJustify, shape)
+		require.NoError(t, err)
+
+		var buf runesio.Buffer
+		require.NoError(t, New().WriteShaped(&buf, runeTokens, AlignJustify, shape))
+		require.Equal(t, strings.Join(expected, "\n")+"\n", string(buf.Runes()))
+	})
+
+	t.Run("should not write anything if the paragraph cannot be set", func(t *testing.T) {
+		var buf runesio.Buffer
+		err := New(WithWordBreak(false)).WriteShaped(&buf, runeTokens, AlignLeft, UniformShape(4))
+		require.ErrorIs(t, err, ErrCannotBeSet)
+		require.Zero(t, buf.Len())
+	})
+}
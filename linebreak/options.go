@@ -1,6 +1,8 @@
 package linebreak
 
 import (
+	"runtime"
+
 	"github.com/fredbi/go-typeset/terminal/runes"
 	wordbreaker "github.com/fredbi/go-typeset/wordbreak"
 )
@@ -13,8 +15,23 @@ type (
 		tolerance float64
 		badness   float64
 		demerits  demeritsT
+		model     DemeritsModel
+		rules     []DemeritsRule
 		formatterOptions
 		looseness int // parameter q in the paper
+
+		pretolerance     float64 // threshold on the adjustment ratio for a first pass without hyphenation. Disabled when negative
+		emergencyStretch float64 // extra stretchability for an emergency pass. Disabled when zero
+		overfull         bool    // enable a final pass allowing overfull lines
+		forceBreak       bool    // enable the forced break of words that don't fit in the final pass
+
+		concurrency int // maximum number of paragraphs broken in parallel
+
+		firstLineIndent    float64 // extra indentation of the first line
+		hangingIndent      float64 // extra indentation of all lines but the first
+		prefix             []rune  // prefix rendered at the start of every line
+		firstLinePrefix    []rune  // prefix rendered at the start of the first line
+		hasFirstLinePrefix bool
 	}
 
 	formatterOptions struct {
@@ -29,6 +46,7 @@ type (
 		punctuationPenalty float64               // penalty to give to punctuation marks
 		hyphenator         wordbreaker.SplitFunc // word breaker for hyphenation
 		punctuator         wordbreaker.SplitFunc // word breaker for punctuations signs (and more generally, all kind of "natural" separators)
+		forceBreakPenalty  float64               // penalty to give to forced breaks of words that don't fit
 		minHyphenate       int                   // minimum length of a token for hyphenation to apply
 		glueStretch        float64
 		glueShrink         float64
@@ -105,15 +123,151 @@ func WithRenderHyphens(enabled bool) Option {
 	}
 }
 
+// WithLooseness sets the desired variation of the number of lines of a paragraph,
+// as compared to the optimal line breaking.
+//
+// A negative looseness produces a tighter paragraph (fewer lines), a positive looseness
+// a looser one (more lines). Whenever the desired number of lines cannot be achieved,
+// the closest feasible number of lines is retained.
+//
+// It corresponds to the parameter q in the original paper.
+//
+// The default is 0.
 func WithLooseness(looseness int) Option {
 	return func(o *options) {
 		o.looseness = looseness
 	}
 }
 
+// WithPretolerance enables a first line breaking pass, with words not being hyphenated,
+// and a threshold on the acceptable adjustment ratio.
+//
+// If this first pass fails, the regular pass, with hyphenation and the tolerance threshold, is run.
+//
+// It corresponds to the \pretolerance parameter in TeX.
+//
+// By default, this pass is disabled (negative value).
+func WithPretolerance(pretolerance float64) Option {
+	return func(o *options) {
+		o.pretolerance = pretolerance
+	}
+}
+
+// WithEmergencyStretch enables an emergency line breaking pass, with some extra stretchability
+// added to every line, whenever the regular pass fails.
+//
+// The stretchability is expressed in the same unit as the line widths (e.g. cells on a terminal).
+//
+// It corresponds to the \emergencystretch parameter in TeX.
+//
+// By default, this pass is disabled (0).
+func WithEmergencyStretch(stretch float64) Option {
+	return func(o *options) {
+		o.emergencyStretch = stretch
+	}
+}
+
+// WithOverfull enables a final line breaking pass, which always succeeds in setting a paragraph,
+// whenever all other passes fail.
+//
+// In this final pass, lines that cannot fit the desired width are rendered overfull.
+// Underfull lines are preferred whenever possible, and the least overfull line is retained otherwise.
+// Overfull lines are reported by Break, in Paragraph.Overfull.
+//
+// By default, this pass is disabled: if no feasible line breaks can be found, ErrCannotBeSet is returned.
+func WithOverfull(enabled bool) Option {
+	return func(o *options) {
+		o.overfull = enabled
+	}
+}
+
+// WithForceBreak enables words that are too wide to fit on a line (e.g. long URLs, hashes)
+// to be broken at any cell boundary, in the final line breaking pass.
+//
+// Forced breaks are not rendered with a hyphen. Words are broken so that every line fits,
+// including a hyphen or a punctuation mark that sticks to the end of a word.
+//
+// It implies WithOverfull(true).
+func WithForceBreak(enabled bool) Option {
+	return func(o *options) {
+		o.forceBreak = enabled
+		if enabled {
+			o.overfull = true
+		}
+	}
+}
+
+// WithDemeritsModel sets a custom model to score lines.
+//
+// The default is KnuthPlassDemerits, with the settings from the original paper.
+func WithDemeritsModel(model DemeritsModel) Option {
+	return func(o *options) {
+		o.model = model
+	}
+}
+
+// WithDemeritsRules adds extra demerits to lines, on top of the demerits model.
+//
+// Rules may be used to express some house style, such as PenalizeWidow or PenalizeShortLastWord.
+func WithDemeritsRules(rules ...DemeritsRule) Option {
+	return func(o *options) {
+		o.rules = append(o.rules, rules...)
+	}
+}
+
+// WithConcurrency sets the maximum number of paragraphs broken in parallel by BreakAll.
+//
+// The default is runtime.GOMAXPROCS(0).
+func WithConcurrency(workers int) Option {
+	return func(o *options) {
+		o.concurrency = workers
+	}
+}
+
+// WithFirstLineIndent indents the first line of a paragraph.
+//
+// The indentation is expressed in the same unit as the line widths (e.g. cells on a terminal).
+// It corresponds to the \parindent parameter in TeX.
+func WithFirstLineIndent(indent float64) Option {
+	return func(o *options) {
+		o.firstLineIndent = indent
+	}
+}
+
+// WithHangingIndent indents all the lines of a paragraph but the first one.
+//
+// Combined with WithFirstLinePrefix, this renders list items with a bullet marker.
+func WithHangingIndent(indent float64) Option {
+	return func(o *options) {
+		o.hangingIndent = indent
+	}
+}
+
+// WithPrefix renders a prefix at the start of every line, before any indentation.
+//
+// This is used to wrap quotes (e.g. "> ") or code comments (e.g. "// ").
+// The width of the prefix is subtracted from the available width of every line.
+func WithPrefix(prefix string) Option {
+	return func(o *options) {
+		o.prefix = []rune(prefix)
+	}
+}
+
+// WithFirstLinePrefix renders a prefix at the start of the first line, in place of the prefix set by WithPrefix.
+//
+// This is used to render list items with a bullet marker (e.g. "- "), with subsequent lines aligned
+// with a blank prefix of the same width, or with a hanging indent.
+func WithFirstLinePrefix(prefix string) Option {
+	return func(o *options) {
+		o.firstLinePrefix = []rune(prefix)
+		o.hasFirstLinePrefix = true
+	}
+}
+
 func defaultOptions(opts []Option) *options {
 	o := &options{
 		tolerance:        8.6,
+		pretolerance:     -1,
 		badness:          100.00, // the badness constant from Knuth&Plass paper
 		demerits:         defaultDemerits(),
 		formatterOptions: defaultFormatterOptions(),
@@ -123,6 +277,19 @@ func defaultOptions(opts []Option) *options {
 		apply(o)
 	}
 
+	if o.model == nil {
+		o.model = &KnuthPlassDemerits{
+			BadnessFactor:   o.badness,
+			LinePenalty:     o.demerits.line,
+			FlaggedDemerits: o.demerits.flagged,
+			FitnessDemerits: o.demerits.fitness,
+		}
+	}
+
+	if o.concurrency <= 0 {
+		o.concurrency = runtime.GOMAXPROCS(0)
+	}
+
 	return o
 }
 
@@ -145,11 +312,12 @@ func defaultFormatterOptions() formatterOptions {
 			stretch: 2, // 6,
 			shrink:  3, // 9,
 		},
-		hyphenPenalty:      300, // penalty applied to breaks after a soft hyphen
-		hardHyphenPenalty:  200, // penalty applied to breaks after an explicit hyphen
-		punctuationPenalty: 400, // penalty applied to break before a punctuation mark
-		minHyphenate:       4,   // minimum length of a word to be hyphenated
-		glueStretch:        6,   // 12 -> 18,
-		glueShrink:         0,   // ,
+		hyphenPenalty:      300,  // penalty applied to breaks after a soft hyphen
+		hardHyphenPenalty:  200,  // penalty applied to breaks after an explicit hyphen
+		punctuationPenalty: 400,  // penalty applied to break before a punctuation mark
+		forceBreakPenalty:  1000, // penalty applied to forced breaks of words that don't fit
+		minHyphenate:       4,    // minimum length of a word to be hyphenated
+		glueStretch:        6,    // 12 -> 18,
+		glueShrink:         0,    // ,
 	}
 }
@@ -0,0 +1,173 @@
+package linebreak
+
+type (
+	// Paragraph is a paragraph set by the LineBreaker, with diagnostics about every line.
+	Paragraph struct {
+		// Lines of the paragraph, as rendered
+		Lines []Line
+
+		// Pass is the line breaking pass which succeeded in setting the paragraph
+		Pass Pass
+
+		// Overfull lists the lines wider than their desired width. Line numbers start at 1.
+		//
+		// Overfull lines may only be produced by the PassOverfull pass.
+		Overfull []int
+
+		// Demerits is the total demerits score for the paragraph
+		Demerits float64
+	}
+
+	// Line is a rendered line, with the diagnostics collected while breaking the paragraph.
+	Line struct {
+		// Text is the rendered line
+		Text []rune
+
+		// Number of the line in the paragraph, starting at 1
+		Number int
+
+		// Width is the desired width for this line
+		Width float64
+
+		// Ratio is the adjustment ratio of the line: negative when shrunk, positive when stretched.
+		//
+		// A ratio below -1 indicates an overfull line.
+		Ratio float64
+
+		// Badness measures how much the line deviates from its natural width
+		Badness float64
+
+		// Demerits is the demerits score for this line
+		Demerits float64
+
+		// Fitness is the fitness class of the line
+		Fitness Fitness
+
+		// Hyphenated is true when the line ends inside a word, at a hyphenation point or after an explicit hyphen
+		Hyphenated bool
+
+		// Tokens is the span of source tokens found on this line
+		Tokens TokenSpan
+
+		// Source is the span of source runes rendered on this line.
+		//
+		// When a word is hyphenated, the span ends inside the word, and the next line starts at this point.
+		Source SourceSpan
+
+		// Segments map the fragments of the rendered line to their source
+		Segments []Segment
+	}
+
+	// TokenSpan is the range of source tokens [Start, End) found on a line.
+	//
+	// A token broken across lines is part of the spans of all these lines.
+	TokenSpan struct {
+		Start int
+		End   int
+	}
+)
+
+// Overfull tells if the line is wider than its desired width.
+func (l Line) Overfull() bool {
+	return l.Ratio < -1
+}
+
+// Underfull tells if the line had to be stretched beyond the stretchability of its spaces.
+func (l Line) Underfull() bool {
+	return l.Ratio > 1
+}
+
+// String representation of the rendered line.
+func (l Line) String() string {
+	return string(l.Text)
+}
+
+// Strings yields the rendered lines of the paragraph.
+func (p Paragraph) Strings() []string {
+	result := make([]string, 0, len(p.Lines))
+	for _, line := range p.Lines {
+		result = append(result, line.String())
+	}
+
+	return result
+}
+
+// Break a paragraph like Shaped, and return the rendered lines with their diagnostics.
+func (l *LineBreaker) Break(tokens []string, align Alignment, shape Shape) (*Paragraph, error) {
+	return l.BreakRunes(toRunes(tokens), align, shape)
+}
+
+// BreakRunes does the same as Break, but takes tokens as slices of runes.
+func (l *LineBreaker) BreakRunes(tokens [][]rune, align Alignment, shape Shape) (*Paragraph, error) {
+	b := l.newBreaker()
+	breakList, err := b.breakParagraph(align, tokens, shape)
+	if err != nil {
+		return nil, err
+	}
+
+	return b.paragraph(breakList), nil
+}
+
+// paragraph collects the rendered lines and their diagnostics.
+func (b *breaker) paragraph(breakList *breakPoint) *Paragraph {
+	texts := b.renderRunes(breakList)
+	paragraph := &Paragraph{
+		Lines:    make([]Line, 0, len(texts)),
+		Pass:     b.pass.pass,
+		Overfull: overfullLines(breakList),
+	}
+
+	brk := breakList.next
+	for _, line := range b.lines(breakList) {
+		last := b.nodes[brk.position]
+		segments := b.segments(line)
+
+		paragraph.Lines = append(paragraph.Lines, Line{
+			Text:       texts[brk.line-1],
+			Number:     brk.line,
+			Width:      b.shape.Line(brk.line).Width,
+			Ratio:      brk.ratio,
+			Badness:    b.model.Badness(brk.ratio),
+			Demerits:   brk.totalDemerits - brk.previous.totalDemerits,
+			Fitness:    b.model.Fitness(brk.ratio),
+			Hyphenated: b.isHyphenation(last),
+			Tokens:     tokenSpan(line.nodes),
+			Source:     sourceSpan(segments),
+			Segments:   segments,
+		})
+		paragraph.Demerits = brk.totalDemerits
+
+		brk = brk.next
+	}
+
+	return paragraph
+}
+
+// isHyphenation tells if a break at this node splits a word.
+func (l *LineBreaker) isHyphenation(node nodeT) bool {
+	return node.isPenalty() && (node.penalty == l.hyphenPenalty || node.penalty == l.hardHyphenPenalty)
+}
+
+// tokenSpan yields the span of source tokens found in the nodes of a line.
+func tokenSpan(nodes []nodeT) TokenSpan {
+	isToken := func(node nodeT) bool { return node.isBox() && node.token != noToken }
+
+	var span TokenSpan
+	for i := 0; i < len(nodes); i++ {
+		if isToken(nodes[i]) {
+			span.Start = nodes[i].token
+
+			break
+		}
+	}
+
+	for i := len(nodes) - 1; i >= 0; i-- {
+		if isToken(nodes[i]) {
+			span.End = nodes[i].token + 1
+
+			break
+		}
+	}
+
+	return span
+}
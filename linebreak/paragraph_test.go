@@ -0,0 +1,100 @@
+package linebreak
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBreak(t *testing.T) {
+	tokens := strings.Fields(grimm)
+
+	t.Run("should render the same lines as Shaped", func(t *testing.T) {
+		for _, align := range []Alignment{AlignLeft, AlignJustify, AlignCenter, AlignRight} {
+			expected, err := New().Shaped(tokens, align, UniformShape(30))
+			require.NoError(t, err)
+
+			paragraph, err := New().Break(tokens, align, UniformShape(30))
+			require.NoError(t, err)
+			require.Equal(t, expected, paragraph.Strings())
+		}
+	})
+
+	t.Run("should expose diagnostics for every line", func(t *testing.T) {
+		lb := New()
+		paragraph, err := lb.Break(tokens, AlignJustify, UniformShape(30))
+		require.NoError(t, err)
+		require.Equal(t, PassRegular, paragraph.Pass)
+		require.NotEmpty(t, paragraph.Lines)
+
+		var (
+			demerits   float64
+			hyphenated bool
+			next       int
+		)
+
+		for i, line := range paragraph.Lines {
+			require.Equal(t, i+1, line.Number)
+			require.Equal(t, 30.0, line.Width)
+			require.False(t, line.Overfull())
+			require.GreaterOrEqual(t, line.Badness, 0.0)
+			require.LessOrEqual(t, line.Ratio, lb.tolerance)
+			require.Equal(t, (&KnuthPlassDemerits{}).Fitness(line.Ratio), line.Fitness)
+			demerits += line.Demerits
+
+			// token spans are contiguous, and overlap only when a word is hyphenated
+			if hyphenated {
+				require.Equal(t, next-1, line.Tokens.Start)
+			} else {
+				require.Equal(t, next, line.Tokens.Start)
+			}
+			require.Greater(t, line.Tokens.End, line.Tokens.Start)
+			next = line.Tokens.End
+
+			hyphenated = line.Hyphenated
+			if hyphenated {
+				require.True(t, strings.HasSuffix(line.String(), "-"))
+			}
+		}
+
+		require.Equal(t, len(tokens), next)
+		require.False(t, hyphenated, "the last line cannot be hyphenated")
+		require.InDelta(t, paragraph.Demerits, demerits, 1e-6*paragraph.Demerits)
+	})
+
+	t.Run("should report the words on a line", func(t *testing.T) {
+		paragraph, err := New(WithWordBreak(false)).Break(tokens, AlignLeft, UniformShape(30))
+		require.NoError(t, err)
+
+		for _, line := range paragraph.Lines {
+			require.Equal(t,
+				strings.Join(tokens[line.Tokens.Start:line.Tokens.End], " "),
+				strings.TrimSpace(line.String()),
+			)
+		}
+	})
+
+	t.Run("should report overfull lines", func(t *testing.T) {
+		const hash = `sha256:9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08`
+		paragraph, err := New(WithOverfull(true)).Break(
+			strings.Fields(`the image digest is `+hash+` as computed`), AlignLeft, UniformShape(30),
+		)
+		require.NoError(t, err)
+		require.Equal(t, PassOverfull, paragraph.Pass)
+
+		var overfull int
+		for _, line := range paragraph.Lines {
+			if line.Overfull() {
+				overfull++
+				require.Equal(t, FitnessTight, line.Fitness)
+			}
+		}
+		require.Equal(t, 1, overfull)
+	})
+
+	t.Run("should not break with an empty shape", func(t *testing.T) {
+		_, err := New().Break(tokens, AlignLeft, nil)
+		require.ErrorIs(t, err, ErrEmptyShape)
+	})
+}
@@ -0,0 +1,140 @@
+package linebreak
+
+import (
+	"github.com/fredbi/go-typeset/attributes"
+)
+
+type (
+	// Pass identifies a line breaking pass.
+	//
+	// Like TeX, the line breaker may run several passes over a paragraph, with increasingly
+	// permissive settings, until one pass succeeds in setting the paragraph.
+	Pass uint8
+
+	passT struct {
+		pass             Pass
+		tolerance        float64 // threshold on the adjustment ratio for this pass
+		hyphenate        bool    // enable hyphenation in this pass
+		emergencyStretch float64 // extra stretchability added to every line (scaled)
+		final            bool    // the final pass always succeeds, possibly with overfull lines
+	}
+)
+
+const (
+	// PassNoHyphenation is a first pass, with words not being hyphenated, and the pretolerance threshold.
+	//
+	// This pass is enabled by WithPretolerance.
+	PassNoHyphenation Pass = iota
+
+	// PassRegular is the regular pass, with the tolerance threshold.
+	PassRegular
+
+	// PassEmergency is a pass with some extra stretchability added to every line.
+	//
+	// This pass is enabled by WithEmergencyStretch.
+	PassEmergency
+
+	// PassOverfull is a final pass that always succeeds, possibly with overfull lines.
+	//
+	// This pass is enabled by WithOverfull or WithForceBreak.
+	PassOverfull
+)
+
+// String representation of a Pass.
+func (p Pass) String() string {
+	switch p {
+	case PassNoHyphenation:
+		return "no-hyphenation"
+	case PassRegular:
+		return "regular"
+	case PassEmergency:
+		return "emergency"
+	case PassOverfull:
+		return "overfull"
+	default:
+		return ""
+	}
+}
+
+// passes yields the line breaking passes to run, in order.
+func (l *LineBreaker) passes() []passT {
+	passes := make([]passT, 0, 4)
+
+	if l.pretolerance >= 0 {
+		passes = append(passes, passT{
+			pass:      PassNoHyphenation,
+			tolerance: l.pretolerance,
+		})
+	}
+
+	passes = append(passes, passT{
+		pass:      PassRegular,
+		tolerance: l.tolerance,
+		hyphenate: l.wordBreak,
+	})
+
+	if l.emergencyStretch > 0 {
+		passes = append(passes, passT{
+			pass:             PassEmergency,
+			tolerance:        l.tolerance,
+			hyphenate:        l.wordBreak,
+			emergencyStretch: l.scale(l.emergencyStretch),
+		})
+	}
+
+	if l.overfull {
+		passes = append(passes, passT{
+			pass:             PassOverfull,
+			tolerance:        l.tolerance,
+			hyphenate:        l.wordBreak,
+			emergencyStretch: l.scale(l.emergencyStretch),
+			final:            true,
+		})
+	}
+
+	return passes
+}
+
+// breakPasses runs the line breaking passes, until one succeeds.
+func (b *breaker) breakPasses(tokens [][]rune) *breakPoint {
+	for _, pass := range b.passes() {
+		b.pass = pass
+
+		// build a model that represent the tokens in terms of glue/box/penalty nodes
+		b.attrList = attributes.NewState()
+		b.nodes = b.buildNodes(tokens)
+
+		// compute a chained-list of break points
+		breakList := b.breakPoints()
+		if breakList == nil {
+			continue
+		}
+
+		return breakList
+	}
+
+	return nil
+}
+
+// overfullLines lists the lines that could not be shrunk enough to fit their desired width.
+func overfullLines(breakList *breakPoint) []int {
+	var overfull []int
+
+	for brk := breakList.next; brk != nil; brk = brk.next {
+		if brk.ratio < -1 {
+			overfull = append(overfull, brk.line)
+		}
+	}
+
+	return overfull
+}
+
+// minLineWidth yields the narrowest (scaled) line width in the paragraph.
+func (b *breaker) minLineWidth() float64 {
+	minWidth := b.lineWidths[0]
+	for _, width := range b.lineWidths[1:] {
+		minWidth = minf(minWidth, width)
+	}
+
+	return minWidth
+}
@@ -0,0 +1,126 @@
+package linebreak
+
+import (
+	"math"
+
+	"github.com/fredbi/go-typeset/terminal/ansi"
+)
+
+type (
+	// Shape describes the geometry of a paragraph, line by line.
+	//
+	// The geometry of the last line specified applies to all subsequent lines.
+	Shape []LineShape
+
+	// LineShape describes the geometry of a single line.
+	LineShape struct {
+		Indent float64 // indentation from the left margin of the paragraph
+		Width  float64 // available width for the line, after the indentation
+	}
+)
+
+// UniformShape builds the shape of a paragraph with all lines of the same width.
+func UniformShape(width float64) Shape {
+	return Shape{{Width: width}}
+}
+
+// ShapeFromWidths builds the shape of a paragraph from a list of line widths.
+//
+// Lines shorter than the widest line are indented, so that all lines are flush right with the widest line.
+// This is the typical shape for a hanging indent, a first line shortened by a label, or text flowing
+// around a left sidebar.
+//
+// The last width applies to all subsequent lines.
+func ShapeFromWidths(widths []float64) Shape {
+	var maxWidth float64
+	for _, width := range widths {
+		if width > maxWidth {
+			maxWidth = width
+		}
+	}
+
+	shape := make(Shape, 0, len(widths))
+	for _, width := range widths {
+		shape = append(shape, LineShape{
+			Indent: maxWidth - width,
+			Width:  width,
+		})
+	}
+
+	return shape
+}
+
+// ShapeFromFunc builds the shape of a paragraph for a given number of lines, from a function of the line number.
+//
+// Line numbers start at 1. The geometry of the last line applies to all subsequent lines.
+func ShapeFromFunc(lines int, fn func(line int) LineShape) Shape {
+	shape := make(Shape, 0, lines)
+	for line := 1; line <= lines; line++ {
+		shape = append(shape, fn(line))
+	}
+
+	return shape
+}
+
+// Line yields the geometry of a line.
+//
+// NOTE: line starts at 1.
+func (s Shape) Line(line int) LineShape {
+	if len(s) == 0 {
+		return LineShape{}
+	}
+
+	if line < len(s)+1 {
+		return s[line-1]
+	}
+
+	return s[len(s)-1]
+}
+
+// indentedShape applies the indentation options and the width of line prefixes to the shape of a paragraph.
+func (l *LineBreaker) indentedShape(shape Shape) Shape {
+	if l.firstLineIndent == 0 && l.hangingIndent == 0 && len(l.prefix) == 0 && len(l.firstLinePrefix) == 0 {
+		return shape
+	}
+
+	lines := len(shape)
+	if lines < 2 {
+		// the first line differs from subsequent ones
+		lines = 2
+	}
+
+	indented := make(Shape, 0, lines)
+	for line := 1; line <= lines; line++ {
+		lineShape := shape.Line(line)
+		indent := l.hangingIndent
+		if line == 1 {
+			indent = l.firstLineIndent
+		}
+
+		indented = append(indented, LineShape{
+			Indent: lineShape.Indent + indent,
+			Width:  lineShape.Width - indent - float64(l.prefixWidth(line)),
+		})
+	}
+
+	return indented
+}
+
+// linePrefix yields the prefix rendered at the start of a line.
+func (l *LineBreaker) linePrefix(line int) []rune {
+	if line == 1 && l.hasFirstLinePrefix {
+		return l.firstLinePrefix
+	}
+
+	return l.prefix
+}
+
+// prefixWidth yields the width of the prefix of a line, ignoring ANSI escape sequences.
+func (l *LineBreaker) prefixWidth(line int) int {
+	prefix := l.linePrefix(line)
+	if len(prefix) == 0 {
+		return 0
+	}
+
+	return int(math.Round(l.measurer(ansi.Strip(prefix))))
+}
@@ -0,0 +1,156 @@
+package linebreak
+
+import (
+	"github.com/fredbi/go-typeset/terminal/ansi"
+	"github.com/fredbi/go-typeset/terminal/runes"
+)
+
+type (
+	// SourcePosition locates a rune in the source tokens of a paragraph.
+	SourcePosition struct {
+		// Token is the index of the source token
+		Token int
+
+		// Offset of the rune in the token, in runes. ANSI escape sequences in the token are accounted for.
+		Offset int
+	}
+
+	// SourceSpan is the range of source runes [Start, End) rendered on a line.
+	SourceSpan struct {
+		Start SourcePosition
+		End   SourcePosition
+	}
+
+	// Segment maps a fragment of text in a rendered line to its source.
+	Segment struct {
+		// Column of the fragment in the rendered line, in cells
+		Column int
+
+		// Width of the fragment, in cells
+		Width int
+
+		// Text of the fragment, stripped from ANSI escape sequences
+		Text []rune
+
+		// Source of the fragment, within a single token
+		Source SourceSpan
+	}
+)
+
+// SourceAt yields the position in the source of the rune rendered at some column of the line.
+//
+// It returns false if no source text is rendered at this column (e.g. a space or an added hyphen).
+func (l Line) SourceAt(column int) (SourcePosition, bool) {
+	for _, segment := range l.Segments {
+		if column < segment.Column || column >= segment.Column+segment.Width {
+			continue
+		}
+
+		cell := segment.Column
+		for i, r := range segment.Text {
+			cell += runes.Width(r)
+			if column < cell {
+				return SourcePosition{
+					Token:  segment.Source.Start.Token,
+					Offset: segment.Source.Start.Offset + i,
+				}, true
+			}
+		}
+	}
+
+	return SourcePosition{}, false
+}
+
+// Position yields the line number and the column where a rune from the source is rendered.
+//
+// It returns false if this rune is not rendered, e.g. an ANSI escape sequence.
+func (p *Paragraph) Position(pos SourcePosition) (line, column int, ok bool) {
+	for _, l := range p.Lines {
+		for _, segment := range l.Segments {
+			if pos.Token != segment.Source.Start.Token ||
+				pos.Offset < segment.Source.Start.Offset || pos.Offset >= segment.Source.End.Offset {
+				continue
+			}
+
+			k := pos.Offset - segment.Source.Start.Offset
+
+			return l.Number, segment.Column + runes.Widths(segment.Text[:k]), true
+		}
+	}
+
+	return 0, 0, false
+}
+
+// segments maps the boxes rendered on a line to their source.
+//
+// This follows the same layout as renderLine.
+func (b *breaker) segments(line lineT) []Segment {
+	indent, pads := b.pads(line)
+	column := b.prefixWidth(line.line) + indent + int(b.shape.Line(line.line).Indent)
+	segments := make([]Segment, 0, len(line.nodes))
+
+	for index, node := range line.nodes {
+		switch {
+		case node.isBox():
+			width := int(b.downScale(node.width))
+
+			if node.token != noToken && len(node.value) > 0 {
+				segments = append(segments, Segment{
+					Column: column,
+					Width:  width,
+					Text:   node.value,
+					Source: SourceSpan{
+						Start: SourcePosition{Token: node.token, Offset: node.offset},
+						End:   SourcePosition{Token: node.token, Offset: node.offset + len(node.value)},
+					},
+				})
+			}
+
+			column += width
+
+		case node.isGlue():
+			column += pads[index]
+		}
+	}
+
+	return segments
+}
+
+// sourceSpan yields the span of source runes covered by the segments of a line.
+func sourceSpan(segments []Segment) SourceSpan {
+	if len(segments) == 0 {
+		return SourceSpan{}
+	}
+
+	return SourceSpan{
+		Start: segments[0].Source.Start,
+		End:   segments[len(segments)-1].Source.End,
+	}
+}
+
+// textOffsets yields the offset in a token of every rune of its text, once stripped from ANSI escape sequences.
+//
+// Break control runes such as soft hyphens and word joiners are not rendered in boxes, and are skipped.
+func textOffsets(token []rune) []int {
+	offsets := make([]int, 0, len(token))
+	var offset int
+
+	for _, stripped := range ansi.StripToken(token) {
+		offset += len(stripped.StartSequence)
+
+		for _, r := range stripped.Text {
+			if isBreakControl(r) {
+				offset++
+
+				continue
+			}
+
+			offsets = append(offsets, offset)
+			offset++
+		}
+
+		offset += len(stripped.StopSequence)
+	}
+
+	return offsets
+}
@@ -0,0 +1,112 @@
+package linebreak
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/fredbi/go-typeset/terminal/ansi"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSource(t *testing.T) {
+	tokens := strings.Fields(grimm)
+
+	t.Run("should map every line to contiguous source spans", func(t *testing.T) {
+		for _, align := range []Alignment{AlignLeft, AlignJustify, AlignCenter, AlignRight} {
+			paragraph, err := New().Break(tokens, align, UniformShape(20))
+			require.NoError(t, err)
+
+			var previous SourceSpan
+			for i, line := range paragraph.Lines {
+				require.NotEmpty(t, line.Segments)
+
+				for _, segment := range line.Segments {
+					source := []rune(tokens[segment.Source.Start.Token])
+					require.Equal(t, segment.Source.Start.Token, segment.Source.End.Token)
+					require.Equal(t,
+						string(source[segment.Source.Start.Offset:segment.Source.End.Offset]),
+						string(segment.Text),
+					)
+					require.Equal(t,
+						string(segment.Text),
+						string(line.Text[segment.Column:segment.Column+segment.Width]),
+					)
+				}
+
+				if i == 0 {
+					require.Equal(t, SourcePosition{}, line.Source.Start)
+				} else if line.Source.Start.Offset > 0 {
+					// a word broken across lines (at a hyphenation point or a punctuation mark) resumes at the split point
+					require.Equal(t, previous.End, line.Source.Start)
+				} else {
+					require.Equal(t, previous.End.Token+1, line.Source.Start.Token)
+				}
+
+				previous = line.Source
+			}
+
+			last := tokens[len(tokens)-1]
+			require.Equal(t, SourcePosition{Token: len(tokens) - 1, Offset: len([]rune(last))}, previous.End)
+		}
+	})
+
+	t.Run("should map the split point of a hyphenated word", func(t *testing.T) {
+		paragraph, err := New().Break([]string{"a", "supercalifragilisticexpialidocious", "word"}, AlignLeft, UniformShape(15))
+		require.NoError(t, err)
+		require.Greater(t, len(paragraph.Lines), 1)
+
+		first := paragraph.Lines[0]
+		require.True(t, first.Hyphenated)
+		require.Equal(t, 1, first.Source.End.Token)
+
+		split := first.Source.End.Offset
+		require.Greater(t, split, 0)
+		require.Equal(t, "a "+"supercalifragilisticexpialidocious"[:split]+"-", strings.TrimSpace(first.String()))
+
+		second := paragraph.Lines[1]
+		require.Equal(t, SourcePosition{Token: 1, Offset: split}, second.Source.Start)
+	})
+
+	t.Run("should account for ANSI escape sequences in offsets", func(t *testing.T) {
+		red := "\x1b[31m" + "red" + "\x1b[0m"
+		tokens := []string{"some", red, "text"}
+
+		paragraph, err := New().Break(tokens, AlignLeft, UniformShape(20))
+		require.NoError(t, err)
+		require.Len(t, paragraph.Lines, 1)
+		require.Equal(t, "some red text", string(ansi.Strip(paragraph.Lines[0].Text)))
+
+		line, column, ok := paragraph.Position(SourcePosition{Token: 1, Offset: len("\x1b[31m")})
+		require.True(t, ok)
+		require.Equal(t, 1, line)
+		require.Equal(t, 5, column)
+
+		_, _, ok = paragraph.Position(SourcePosition{Token: 1, Offset: 0})
+		require.False(t, ok, "escape sequences are not rendered")
+
+		pos, ok := paragraph.Lines[0].SourceAt(7)
+		require.True(t, ok)
+		require.Equal(t, SourcePosition{Token: 1, Offset: len("\x1b[31m") + 2}, pos)
+	})
+
+	t.Run("should map cursor positions back and forth", func(t *testing.T) {
+		paragraph, err := New().Break(tokens, AlignJustify, UniformShape(25))
+		require.NoError(t, err)
+
+		for _, line := range paragraph.Lines {
+			for column := range line.Text {
+				pos, ok := line.SourceAt(column)
+				if !ok {
+					require.Contains(t, []rune{' ', '-'}, line.Text[column])
+
+					continue
+				}
+
+				number, at, ok := paragraph.Position(pos)
+				require.True(t, ok)
+				require.Equal(t, line.Number, number)
+				require.Equal(t, column, at)
+			}
+		}
+	})
+}
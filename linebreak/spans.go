@@ -0,0 +1,124 @@
+package linebreak
+
+import (
+	"strings"
+
+	"github.com/fredbi/go-typeset/terminal/ansi"
+	"github.com/fredbi/go-typeset/wordbreak/tokenizer"
+)
+
+// Runes with a special meaning for the line breaker, when found inside tokens.
+const (
+	// NoBreakSpace (U+00A0) is rendered as a space which cannot be broken.
+	//
+	// When justifying text, it stretches and shrinks like a regular space.
+	NoBreakSpace = tokenizer.NoBreakSpace
+
+	// FigureSpace (U+2007) is rendered as a space which cannot be broken, and never stretches nor shrinks.
+	//
+	// It is used to group the digits of numbers, e.g. "1 000 000".
+	FigureSpace = tokenizer.FigureSpace
+
+	// NarrowNoBreakSpace (U+202F) is rendered as a space which cannot be broken, and never stretches nor shrinks.
+	NarrowNoBreakSpace = tokenizer.NarrowNoBreakSpace
+
+	// WordJoiner (U+2060) is not rendered, and prevents any break at its position.
+	WordJoiner = '\u2060'
+
+	// SoftHyphen (U+00AD) marks a legit hyphenation point. It is only rendered when the word is hyphenated there.
+	//
+	// Words with soft hyphens are not hyphenated elsewhere.
+	SoftHyphen = '\u00ad'
+)
+
+// KeepTogether joins the tokens in the range [start, end) with no-break spaces, so they
+// are always set on the same line.
+//
+// The joined tokens are replaced by a single token: token indices reported
+// in diagnostics refer to the returned tokens.
+func KeepTogether(tokens []string, start, end int) ([]string, error) {
+	if start < 0 || end > len(tokens) || start > end {
+		return nil, ErrInvalidSpan
+	}
+
+	if end-start < 2 {
+		return tokens, nil
+	}
+
+	result := make([]string, 0, len(tokens)-(end-start)+1)
+	result = append(result, tokens[:start]...)
+	result = append(result, strings.Join(tokens[start:end], string(NoBreakSpace)))
+
+	return append(result, tokens[end:]...), nil
+}
+
+// NoHyphenate marks the tokens in the range [start, end) so they are never broken:
+// word joiners are inserted between all the runes of these tokens.
+//
+// This prevents hyphenation, as well as breaks after punctuation marks or explicit hyphens.
+// The returned tokens are a copy: the input tokens are not altered.
+func NoHyphenate(tokens []string, start, end int) ([]string, error) {
+	if start < 0 || end > len(tokens) || start > end {
+		return nil, ErrInvalidSpan
+	}
+
+	result := make([]string, len(tokens))
+	copy(result, tokens)
+
+	for i := start; i < end; i++ {
+		result[i] = joinRunes([]rune(tokens[i]))
+	}
+
+	return result, nil
+}
+
+// joinRunes inserts a word joiner after every rune of the text of a token but the last one,
+// leaving ANSI escape sequences untouched.
+func joinRunes(token []rune) string {
+	pieces := ansi.StripToken(token)
+
+	var count int
+	for _, stripped := range pieces {
+		count += len(stripped.Text)
+	}
+
+	var w strings.Builder
+	w.Grow(len(token) + 3*count)
+
+	for _, stripped := range pieces {
+		w.WriteString(string(stripped.StartSequence))
+
+		for _, r := range stripped.Text {
+			w.WriteRune(r)
+
+			count--
+			if count > 0 {
+				w.WriteRune(WordJoiner)
+			}
+		}
+
+		w.WriteString(string(stripped.StopSequence))
+	}
+
+	return w.String()
+}
+
+// isNoBreakSpace tells if a rune is a space that cannot be broken.
+func isNoBreakSpace(r rune) bool {
+	return r == NoBreakSpace || r == FigureSpace || r == NarrowNoBreakSpace
+}
+
+// isBreakControl tells if a rune is not rendered in boxes, but controls line breaks.
+func isBreakControl(r rune) bool {
+	return isNoBreakSpace(r) || r == WordJoiner || r == SoftHyphen
+}
+
+func hasRune(word []rune, r rune) bool {
+	for _, c := range word {
+		if c == r {
+			return true
+		}
+	}
+
+	return false
+}
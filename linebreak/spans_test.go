@@ -0,0 +1,177 @@
+package linebreak
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/fredbi/go-typeset/wordbreak/tokenizer"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSpans(t *testing.T) {
+	allAlignments := []Alignment{AlignLeft, AlignJustify, AlignCenter, AlignRight}
+
+	t.Run("should never break at a no-break space", func(t *testing.T) {
+		text := "As shown in Fig. 3 and Fig. 4, the results of the experiment in Fig. 5 are conclusive."
+		tokens := strings.Split(text, " ")
+		lb := New(WithEmergencyStretch(10))
+
+		for _, align := range allAlignments {
+			for width := 12; width <= 30; width++ {
+				lines, err := lb.Shaped(tokens, align, UniformShape(float64(width)))
+				require.NoError(t, err)
+
+				for _, line := range lines {
+					require.NotContains(t, line, " ")
+					require.False(t, strings.HasSuffix(strings.TrimSpace(line), "Fig."),
+						"unexpected break after %q with alignment %v, width %d", line, align, width,
+					)
+				}
+
+				joined := collapsed(lines)
+				for _, ref := range []string{"Fig. 3", "Fig. 4", "Fig. 5"} {
+					require.Contains(t, joined, ref)
+				}
+			}
+		}
+	})
+
+	t.Run("should stretch a no-break space, but not a narrow no-break space", func(t *testing.T) {
+		tokens := strings.Fields("a distance of 10 km and then some more")
+
+		lines, err := New().Shaped(tokens, AlignJustify, UniformShape(20))
+		require.NoError(t, err)
+		require.Greater(t, len(lines), 1)
+
+		joined := strings.Join(lines, "\n")
+		require.Contains(t, joined, "10 km")
+		require.NotContains(t, joined, " ")
+	})
+
+	t.Run("should never break nor stretch at a figure space", func(t *testing.T) {
+		var tokens []string
+		for _, token := range tokenizer.New().BreakWordString("a total of 1\u2007000\u2007000 units, and then 250\u2007000 more units") {
+			tokens = append(tokens, string(token))
+		}
+		require.Len(t, tokens, 10)
+
+		for _, align := range allAlignments {
+			for width := 11; width <= 30; width++ {
+				lines, err := New().Shaped(tokens, align, UniformShape(float64(width)))
+				require.NoError(t, err)
+
+				joined := strings.Join(lines, "\n")
+				require.Contains(t, joined, "1 000 000", "unexpected break at a figure space (alignment: %v, width: %d)", align, width)
+				require.Contains(t, joined, "250 000", "unexpected break at a figure space (alignment: %v, width: %d)", align, width)
+				require.NotContains(t, joined, "\u2007")
+			}
+		}
+	})
+
+	t.Run("should hyphenate only at soft hyphens", func(t *testing.T) {
+		tokens := []string{"an", "extra­ordinary", "day"}
+
+		paragraph, err := New().Break(tokens, AlignLeft, UniformShape(10))
+		require.NoError(t, err)
+		require.Equal(t, []string{"an extra-", "ordinary", "day"}, trimmed(paragraph.Strings()))
+		require.True(t, paragraph.Lines[0].Hyphenated)
+
+		t.Run("with source positions that skip soft hyphens", func(t *testing.T) {
+			second := paragraph.Lines[1]
+			require.Equal(t, SourcePosition{Token: 1, Offset: 6}, second.Source.Start)
+			require.Equal(t, SourcePosition{Token: 1, Offset: 5}, paragraph.Lines[0].Source.End)
+		})
+
+		t.Run("with soft hyphens not rendered when the word is not broken", func(t *testing.T) {
+			lines, err := New().Shaped(tokens, AlignLeft, UniformShape(30))
+			require.NoError(t, err)
+			require.Equal(t, []string{"an extraordinary day"}, trimmed(lines))
+		})
+	})
+
+	t.Run("should never break at a word joiner", func(t *testing.T) {
+		tokens := []string{"see", "item⁠/⁠subitem", "for", "details"}
+
+		for _, align := range allAlignments {
+			lines, err := New(WithEmergencyStretch(10)).Shaped(tokens, align, UniformShape(14))
+			require.NoError(t, err)
+
+			joined := strings.Join(trimmed(lines), "\n")
+			require.Contains(t, joined, "item/subitem")
+			require.NotContains(t, joined, "⁠")
+		}
+	})
+
+	t.Run("should keep tokens together", func(t *testing.T) {
+		tokens := strings.Fields("one two three four five six seven eight")
+
+		kept, err := KeepTogether(tokens, 2, 5)
+		require.NoError(t, err)
+		require.Len(t, kept, 6)
+		require.Equal(t, "three four five", kept[2])
+		require.Equal(t, "six", kept[3])
+
+		for _, align := range allAlignments {
+			lines, err := New(WithEmergencyStretch(10)).Shaped(kept, align, UniformShape(16))
+			require.NoError(t, err)
+			require.Contains(t, collapsed(lines), "three four five\n")
+		}
+
+		_, err = KeepTogether(tokens, 5, 9)
+		require.ErrorIs(t, err, ErrInvalidSpan)
+	})
+
+	t.Run("should not hyphenate marked tokens", func(t *testing.T) {
+		tokens := strings.Fields(grimm)
+
+		unbroken, err := NoHyphenate(tokens, 0, len(tokens))
+		require.NoError(t, err)
+		require.NotEqual(t, tokens, unbroken)
+		require.Equal(t, strings.Fields(grimm), tokens, "input tokens should not be altered")
+
+		for _, align := range allAlignments {
+			paragraph, err := New().Break(unbroken, align, UniformShape(25))
+			require.NoError(t, err)
+
+			for _, line := range paragraph.Lines {
+				require.False(t, line.Hyphenated)
+			}
+
+			require.Equal(t, tokens, strings.Fields(strings.Join(paragraph.Strings(), " ")))
+		}
+
+		_, err = NoHyphenate(tokens, -1, 2)
+		require.ErrorIs(t, err, ErrInvalidSpan)
+	})
+
+	t.Run("should preserve ANSI escape sequences", func(t *testing.T) {
+		token := "\x1b[1mbold\x1b[0mtext"
+		unbroken, err := NoHyphenate([]string{token}, 0, 1)
+		require.NoError(t, err)
+		require.Equal(t, "\x1b[1mb⁠o⁠l⁠d⁠\x1b[0mt⁠e⁠x⁠t", unbroken[0])
+
+		lines, err := New().Shaped(unbroken, AlignLeft, UniformShape(20))
+		require.NoError(t, err)
+		require.Equal(t, []string{token}, trimmed(lines))
+	})
+}
+
+// collapsed joins lines with a line feed, collapsing the spaces within every line.
+func collapsed(lines []string) string {
+	var w strings.Builder
+	for _, line := range lines {
+		w.WriteString(strings.Join(strings.Fields(line), " "))
+		w.WriteByte('\n')
+	}
+
+	return w.String()
+}
+
+func trimmed(lines []string) []string {
+	result := make([]string, 0, len(lines))
+	for _, line := range lines {
+		result = append(result, strings.TrimRight(line, " "))
+	}
+
+	return result
+}
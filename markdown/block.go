@@ -0,0 +1,366 @@
+package markdown
+
+import (
+	"regexp"
+	"strings"
+)
+
+type (
+	// Kind of Markdown block.
+	Kind uint8
+
+	// Block is a Markdown block.
+	//
+	// Leaf blocks hold their source lines, relative to the enclosing container.
+	// Containers (i.e. block quotes and list items) hold other blocks.
+	Block struct {
+		Kind Kind
+
+		// Lines of a leaf block
+		Lines []string
+
+		// Marker of a list item, with the spaces up to its content, e.g. "- " or "1. "
+		Marker string
+
+		// Children of a container block
+		Children []Block
+	}
+)
+
+const (
+	// KindBlank is a run of blank lines
+	KindBlank Kind = iota
+	// KindParagraph is a paragraph of prose, which may be re-wrapped
+	KindParagraph
+	// KindHeading is an ATX ("# Title") or setext heading
+	KindHeading
+	// KindCode is a fenced or indented code block
+	KindCode
+	// KindTable is a table
+	KindTable
+	// KindHTML is a block of raw HTML
+	KindHTML
+	// KindLinkReference is a link reference definition, e.g. "[label]: https://example.com"
+	KindLinkReference
+	// KindThematicBreak is a horizontal rule, e.g. "---"
+	KindThematicBreak
+	// KindQuote is a block quote
+	KindQuote
+	// KindListItem is an item of a bullet or ordered list
+	KindListItem
+)
+
+var (
+	rexATXHeading    = regexp.MustCompile(`^ {0,3}#{1,6}(\s|$)`)
+	rexSetext        = regexp.MustCompile(`^ {0,3}(=+|-+)\s*$`)
+	rexListItem      = regexp.MustCompile(`^( {0,3}(?:[-+*]|\d{1,9}[.)]))(\s+|$)`)
+	rexLinkReference = regexp.MustCompile(`^ {0,3}\[[^\]]+\]:`)
+	rexHTML          = regexp.MustCompile(`^ {0,3}<[A-Za-z/!?]`)
+	rexDelimiterRow  = regexp.MustCompile(`^ {0,3}\|?\s*:?-+:?\s*(\|\s*:?-+:?\s*)*\|?\s*$`)
+	rexQuote         = regexp.MustCompile(`^ {0,3}> ?`)
+)
+
+// String representation of a Kind of block.
+func (k Kind) String() string {
+	switch k {
+	case KindBlank:
+		return "blank"
+	case KindParagraph:
+		return "paragraph"
+	case KindHeading:
+		return "heading"
+	case KindCode:
+		return "code"
+	case KindTable:
+		return "table"
+	case KindHTML:
+		return "html"
+	case KindLinkReference:
+		return "link reference"
+	case KindThematicBreak:
+		return "thematic break"
+	case KindQuote:
+		return "quote"
+	case KindListItem:
+		return "list item"
+	default:
+		return ""
+	}
+}
+
+// Parse the lines of a Markdown document into blocks.
+func Parse(lines []string) []Block {
+	var blocks []Block
+
+	for i := 0; i < len(lines); {
+		var block Block
+		block, i = parseBlock(lines, i)
+		blocks = append(blocks, block)
+	}
+
+	return blocks
+}
+
+// parseBlock parses the block starting at line i, and returns the index of the next line.
+func parseBlock(lines []string, i int) (Block, int) {
+	line := lines[i]
+
+	switch {
+	case isBlank(line):
+		j := i + 1
+		for j < len(lines) && isBlank(lines[j]) {
+			j++
+		}
+
+		return Block{Kind: KindBlank, Lines: lines[i:j]}, j
+
+	case isFence(line):
+		return parseFence(lines, i)
+
+	case rexATXHeading.MatchString(line):
+		return Block{Kind: KindHeading, Lines: lines[i : i+1]}, i + 1
+
+	case isThematicBreak(line):
+		return Block{Kind: KindThematicBreak, Lines: lines[i : i+1]}, i + 1
+
+	case rexQuote.MatchString(line):
+		return parseQuote(lines, i)
+
+	case rexListItem.MatchString(line):
+		return parseListItem(lines, i)
+
+	case isIndentedCode(line):
+		j := i + 1
+		for j < len(lines) && (isIndentedCode(lines[j]) || isBlank(lines[j]) && j+1 < len(lines) && isIndentedCode(lines[j+1])) {
+			j++
+		}
+
+		return Block{Kind: KindCode, Lines: lines[i:j]}, j
+
+	case rexHTML.MatchString(line):
+		return Block{Kind: KindHTML, Lines: lines[i:nextBlank(lines, i)]}, nextBlank(lines, i)
+
+	case rexLinkReference.MatchString(line):
+		return Block{Kind: KindLinkReference, Lines: lines[i : i+1]}, i + 1
+
+	case isTable(lines, i):
+		return Block{Kind: KindTable, Lines: lines[i:nextBlank(lines, i)]}, nextBlank(lines, i)
+
+	default:
+		return parseParagraph(lines, i)
+	}
+}
+
+func parseFence(lines []string, i int) (Block, int) {
+	fence := strings.TrimLeft(lines[i], " ")
+	marker := fence[:len(fence)-len(strings.TrimLeft(fence, fence[:1]))]
+
+	j := i + 1
+	for j < len(lines) {
+		closing := strings.TrimSpace(lines[j])
+		j++
+
+		if strings.HasPrefix(closing, marker) && strings.Trim(closing, marker[:1]) == "" {
+			break
+		}
+	}
+
+	return Block{Kind: KindCode, Lines: lines[i:j]}, j
+}
+
+func parseQuote(lines []string, i int) (Block, int) {
+	var content []string
+
+	j := i
+	for j < len(lines) {
+		line := lines[j]
+
+		if loc := rexQuote.FindStringIndex(line); loc != nil {
+			content = append(content, line[loc[1]:])
+			j++
+
+			continue
+		}
+
+		if isLazyContinuation(content, line) {
+			content = append(content, strings.TrimLeft(line, " "))
+			j++
+
+			continue
+		}
+
+		break
+	}
+
+	return Block{Kind: KindQuote, Children: Parse(content)}, j
+}
+
+func parseListItem(lines []string, i int) (Block, int) {
+	match := rexListItem.FindStringSubmatch(lines[i])
+	marker, spaces := match[1], match[2]
+	content := []string{lines[i][len(match[0]):]}
+
+	if isBlank(content[0]) || len(spaces) > 4 || strings.Contains(spaces, "\t") {
+		// an empty item, or an item starting with indented code: the content starts after a single space
+		content[0] = strings.TrimPrefix(lines[i][len(marker):], " ")
+		spaces = " "
+	}
+
+	marker += spaces
+	indent := len(marker)
+
+	j := i + 1
+	for j < len(lines) {
+		line := lines[j]
+
+		switch {
+		case isBlank(line):
+			k := j
+			for k < len(lines) && isBlank(lines[k]) {
+				k++
+			}
+
+			if k == len(lines) || indentation(lines[k]) < indent {
+				// blank lines after the item
+				return Block{Kind: KindListItem, Marker: marker, Children: Parse(content)}, j
+			}
+
+			for ; j < k; j++ {
+				content = append(content, "")
+			}
+
+		case indentation(line) >= indent:
+			content = append(content, dedent(line, indent))
+			j++
+
+		case isLazyContinuation(content, line):
+			content = append(content, strings.TrimLeft(line, " "))
+			j++
+
+		default:
+			return Block{Kind: KindListItem, Marker: marker, Children: Parse(content)}, j
+		}
+	}
+
+	return Block{Kind: KindListItem, Marker: marker, Children: Parse(content)}, j
+}
+
+func parseParagraph(lines []string, i int) (Block, int) {
+	j := i + 1
+	for j < len(lines) {
+		if rexSetext.MatchString(lines[j]) {
+			return Block{Kind: KindHeading, Lines: lines[i : j+1]}, j + 1
+		}
+
+		if isBlank(lines[j]) || interrupts(lines[j]) {
+			break
+		}
+
+		j++
+	}
+
+	return Block{Kind: KindParagraph, Lines: lines[i:j]}, j
+}
+
+// interrupts tells if a line starts a new block, and interrupts a paragraph.
+func interrupts(line string) bool {
+	return isFence(line) ||
+		rexATXHeading.MatchString(line) ||
+		isThematicBreak(line) ||
+		rexQuote.MatchString(line) ||
+		rexListItem.MatchString(line) ||
+		rexHTML.MatchString(line)
+}
+
+// isLazyContinuation tells if a line continues the paragraph at the end of some content.
+func isLazyContinuation(content []string, line string) bool {
+	if len(content) == 0 || isBlank(line) || interrupts(line) {
+		return false
+	}
+
+	last := content[len(content)-1]
+
+	return !isBlank(last) && !isFence(last) && !isIndentedCode(last) && !rexATXHeading.MatchString(last)
+}
+
+func isBlank(line string) bool {
+	return strings.TrimSpace(line) == ""
+}
+
+func isFence(line string) bool {
+	trimmed := strings.TrimLeft(line, " ")
+
+	return indentation(line) < 4 && (strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~"))
+}
+
+func isIndentedCode(line string) bool {
+	return !isBlank(line) && indentation(line) >= 4
+}
+
+func isThematicBreak(line string) bool {
+	if indentation(line) >= 4 {
+		return false
+	}
+
+	stripped := strings.Join(strings.Fields(line), "")
+	if len(stripped) < 3 || !strings.ContainsAny(stripped[:1], "-*_") {
+		return false
+	}
+
+	return strings.Trim(stripped, stripped[:1]) == ""
+}
+
+func isTable(lines []string, i int) bool {
+	return i+1 < len(lines) &&
+		strings.Contains(lines[i], "|") &&
+		strings.Contains(lines[i+1], "|") &&
+		rexDelimiterRow.MatchString(lines[i+1])
+}
+
+// indentation of a line, in columns. Tabs count for 4 columns.
+func indentation(line string) int {
+	var columns int
+	for _, r := range line {
+		switch r {
+		case ' ':
+			columns++
+		case '\t':
+			columns += 4 - columns%4
+		default:
+			return columns
+		}
+	}
+
+	return columns
+}
+
+// dedent removes some columns of indentation from a line. Tabs are expanded as needed.
+func dedent(line string, columns int) string {
+	var current int
+	for i, r := range line {
+		if current >= columns {
+			return strings.Repeat(" ", current-columns) + line[i:]
+		}
+
+		switch r {
+		case ' ':
+			current++
+		case '\t':
+			current += 4 - current%4
+		default:
+			return line[i:]
+		}
+	}
+
+	return ""
+}
+
+func nextBlank(lines []string, i int) int {
+	for j := i; j < len(lines); j++ {
+		if isBlank(lines[j]) {
+			return j
+		}
+	}
+
+	return len(lines)
+}
@@ -0,0 +1,13 @@
+// Package markdown reflows Markdown documents to a fixed width.
+//
+// A document is parsed into blocks: paragraphs, headings, fenced or indented code, tables, HTML blocks,
+// link reference definitions, thematic breaks, as well as block quotes and list items, which contain other blocks.
+//
+// Only paragraphs are re-wrapped by a linebreak.LineBreaker. List markers are kept as hanging indents,
+// and block quotes keep their "> " prefix. All other blocks are left untouched.
+//
+// In Markdown, a line break within a paragraph is rendered as a space. Lines are therefore only broken
+// at spaces: words are never hyphenated, inline code spans and URLs are never broken, and words which
+// would start a new block at the beginning of a line (e.g. "-", "1.", "#") are kept with the previous word.
+// Hard line breaks (a backslash, or two spaces at the end of a line) are preserved.
+package markdown
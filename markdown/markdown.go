@@ -0,0 +1,278 @@
+package markdown
+
+import (
+	"io"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/fredbi/go-typeset/linebreak"
+	"github.com/fredbi/go-typeset/terminal/runes"
+)
+
+type (
+	// Formatter reflows Markdown documents to a fixed width.
+	Formatter struct {
+		width int
+		*options
+	}
+
+	err string
+)
+
+// ErrTooNarrow is returned when the width cannot hold any text within nested block quotes or list items.
+const ErrTooNarrow err = "the width is too narrow to fit text within nested blocks"
+
+const quotePrefix = "> "
+
+// rexBlockStart matches words which would start a new block at the beginning of a line.
+var rexBlockStart = regexp.MustCompile(`^(#{1,6}|[-+*]|\d{1,9}[.)]|[-=*_]{2,}|[><].*|(` + "```|~~~" + `).*)$`)
+
+// New Formatter, to reflow Markdown within some width, expressed in cells.
+func New(width int, opts ...Option) *Formatter {
+	return &Formatter{
+		width:   width,
+		options: defaultOptions(opts),
+	}
+}
+
+// Format reads a Markdown document from r, and writes the reflowed document to w.
+func (f *Formatter) Format(w io.Writer, r io.Reader) error {
+	doc, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+
+	result, err := f.FormatString(string(doc))
+	if err != nil {
+		return err
+	}
+
+	_, err = io.WriteString(w, result)
+
+	return err
+}
+
+// FormatString reflows a Markdown document.
+func (f *Formatter) FormatString(doc string) (string, error) {
+	trailing := strings.HasSuffix(doc, "\n")
+	lines, err := f.FormatLines(strings.Split(strings.TrimSuffix(doc, "\n"), "\n"))
+	if err != nil {
+		return "", err
+	}
+
+	result := strings.Join(lines, "\n")
+	if trailing {
+		result += "\n"
+	}
+
+	return result, nil
+}
+
+// FormatLines reflows the lines of a Markdown document.
+func (f *Formatter) FormatLines(lines []string) ([]string, error) {
+	return f.render(Parse(lines), f.width)
+}
+
+// render blocks within some width.
+func (f *Formatter) render(blocks []Block, width int) ([]string, error) {
+	result := make([]string, 0, len(blocks))
+
+	for _, block := range blocks {
+		switch block.Kind {
+		case KindParagraph:
+			lines, err := f.wrap(block.Lines, width)
+			if err != nil {
+				return nil, err
+			}
+
+			result = append(result, lines...)
+
+		case KindBlank:
+			for range block.Lines {
+				result = append(result, "")
+			}
+
+		case KindQuote:
+			lines, err := f.render(block.Children, width-len(quotePrefix))
+			if err != nil {
+				return nil, err
+			}
+
+			result = append(result, prefixLines(lines, quotePrefix, quotePrefix)...)
+
+		case KindListItem:
+			lines, err := f.render(block.Children, width-runes.StringWidth(block.Marker))
+			if err != nil {
+				return nil, err
+			}
+
+			if len(lines) == 0 {
+				lines = []string{""}
+			}
+
+			result = append(result, prefixLines(lines, block.Marker, strings.Repeat(" ", len(block.Marker)))...)
+
+		default:
+			// other blocks are left untouched
+			result = append(result, block.Lines...)
+		}
+	}
+
+	return result, nil
+}
+
+// wrap the lines of a paragraph.
+//
+// Hard line breaks are preserved: the text between hard line breaks is wrapped separately.
+func (f *Formatter) wrap(lines []string, width int) ([]string, error) {
+	if width < 1 {
+		return nil, ErrTooNarrow
+	}
+
+	result := make([]string, 0, len(lines))
+	var text []string
+
+	for i, line := range lines {
+		text = append(text, strings.TrimSpace(line))
+
+		hardBreak := ""
+		if i < len(lines)-1 {
+			hardBreak = hardLineBreak(line)
+		}
+
+		if hardBreak == "" && i < len(lines)-1 {
+			continue
+		}
+
+		wrapped, err := f.lb.Shaped(tokenize(strings.Join(text, " ")), linebreak.AlignLeft, linebreak.UniformShape(float64(width)))
+		if err != nil {
+			return nil, err
+		}
+
+		for j := range wrapped {
+			wrapped[j] = strings.TrimRightFunc(wrapped[j], unicode.IsSpace)
+		}
+
+		if hardBreak == "  " {
+			wrapped[len(wrapped)-1] += hardBreak
+		}
+
+		result = append(result, wrapped...)
+		text = text[:0]
+	}
+
+	return result, nil
+}
+
+// hardLineBreak yields the marker of a hard line break at the end of a line: a backslash or two spaces.
+func hardLineBreak(line string) string {
+	switch {
+	case strings.HasSuffix(line, "\\"):
+		return "\\"
+	case strings.HasSuffix(line, "  "):
+		return "  "
+	default:
+		return ""
+	}
+}
+
+// tokenize the text of a paragraph into words.
+//
+// Spaces within inline code spans do not separate words, so code spans are never broken.
+// Words which would start a new block at the beginning of a line are kept with the previous word.
+func tokenize(text string) []string {
+	var (
+		words []string
+		word  strings.Builder
+	)
+
+	flush := func() {
+		if word.Len() == 0 {
+			return
+		}
+
+		current := word.String()
+		word.Reset()
+
+		if len(words) > 0 && rexBlockStart.MatchString(current) {
+			words[len(words)-1] += " " + current
+
+			return
+		}
+
+		words = append(words, current)
+	}
+
+	for i := 0; i < len(text); {
+		switch {
+		case text[i] == '`':
+			// an inline code span, closed by a run of backticks of the same length
+			run := len(text[i:]) - len(strings.TrimLeft(text[i:], "`"))
+			end := closingBackticks(text, i+run, run)
+			word.WriteString(text[i:end])
+			i = end
+
+		case text[i] == ' ' || text[i] == '\t':
+			flush()
+			i++
+
+		default:
+			word.WriteByte(text[i])
+			i++
+		}
+	}
+
+	flush()
+
+	return words
+}
+
+// closingBackticks yields the end of a code span opened with a run of backticks, or the end of this run
+// if the code span is not closed.
+func closingBackticks(text string, start, run int) int {
+	for i := start; i < len(text); {
+		if text[i] != '`' {
+			i++
+
+			continue
+		}
+
+		closing := len(text[i:]) - len(strings.TrimLeft(text[i:], "`"))
+		if closing == run {
+			return i + closing
+		}
+
+		i += closing
+	}
+
+	return start
+}
+
+// prefixLines renders lines with a prefix on the first line, and another prefix on subsequent lines.
+//
+// Blank lines are rendered without trailing spaces.
+func prefixLines(lines []string, first, next string) []string {
+	result := make([]string, 0, len(lines))
+
+	for i, line := range lines {
+		prefix := next
+		if i == 0 {
+			prefix = first
+		}
+
+		if isBlank(line) {
+			result = append(result, strings.TrimRightFunc(prefix, unicode.IsSpace))
+
+			continue
+		}
+
+		result = append(result, prefix+line)
+	}
+
+	return result
+}
+
+func (e err) Error() string {
+	return string(e)
+}
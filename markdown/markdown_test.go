@@ -0,0 +1,196 @@
+package markdown
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const readme = "# go-typeset\n" +
+	"\n" +
+	"Typesetting utilities for the terminal, with the line breaking algorithm from Knuth and Plass, " +
+	"so that `linebreak.New()` wraps text nicely. See https://github.com/fredbi/go-typeset/tree/master/docs for details.\n" +
+	"\n" +
+	"## Features\n" +
+	"\n" +
+	"- a line breaker, which wraps words nicely under width and alignment constraints, with hyphenation\n" +
+	"- a table renderer\n" +
+	"  with wrapped cells\n" +
+	"\n" +
+	"1. first, parse the document into blocks, such as paragraphs, lists, block quotes, headings and code\n" +
+	"2. then, wrap paragraphs\n" +
+	"\n" +
+	"> A block quote, with a rather long line of text which should be wrapped within the width of the document.\n" +
+	"\n" +
+	"```go\n" +
+	"lines, err := linebreak.New().Shaped(tokens, linebreak.AlignLeft, linebreak.UniformShape(30)) // a long line of code\n" +
+	"```\n" +
+	"\n" +
+	"| Column | Description which is rather long, and should never be wrapped since this is a table |\n" +
+	"|--------|------|\n" +
+	"| a      | b    |\n" +
+	"\n" +
+	"[typeset]: https://github.com/fredbi/go-typeset/tree/master/docs/breaking-paragraphs-into-lines.pdf\n"
+
+func TestParse(t *testing.T) {
+	blocks := Parse(strings.Split(strings.TrimSuffix(readme, "\n"), "\n"))
+
+	kinds := make([]Kind, 0, len(blocks))
+	for _, block := range blocks {
+		if block.Kind != KindBlank {
+			kinds = append(kinds, block.Kind)
+		}
+	}
+
+	require.Equal(t, []Kind{
+		KindHeading, KindParagraph, KindHeading,
+		KindListItem, KindListItem, KindListItem, KindListItem,
+		KindQuote, KindCode, KindTable, KindLinkReference,
+	}, kinds)
+
+	t.Run("should parse list items with continuation lines", func(t *testing.T) {
+		item := blocks[7]
+		require.Equal(t, KindListItem, item.Kind)
+		require.Equal(t, "- ", item.Marker)
+		require.Equal(t, []Block{{Kind: KindParagraph, Lines: []string{"a table renderer", "with wrapped cells"}}}, item.Children)
+	})
+
+	t.Run("should parse nested blocks", func(t *testing.T) {
+		nested := Parse([]string{
+			"> - quoted item",
+			">",
+			">       quoted code",
+			"",
+			"Setext heading",
+			"===",
+			"",
+			"***",
+		})
+		require.Len(t, nested, 5)
+		require.Equal(t, KindQuote, nested[0].Kind)
+		require.Len(t, nested[0].Children, 1)
+
+		item := nested[0].Children[0]
+		require.Equal(t, KindListItem, item.Kind)
+		require.Len(t, item.Children, 3)
+		require.Equal(t, KindParagraph, item.Children[0].Kind)
+		require.Equal(t, KindBlank, item.Children[1].Kind)
+		require.Equal(t, []string{"    quoted code"}, item.Children[2].Lines)
+		require.Equal(t, KindHeading, nested[2].Kind)
+		require.Equal(t, KindThematicBreak, nested[4].Kind)
+	})
+}
+
+func TestFormat(t *testing.T) {
+	t.Run("should reflow a document", func(t *testing.T) {
+		output, err := New(40).FormatString(readme)
+		require.NoError(t, err)
+		require.True(t, strings.HasSuffix(output, "\n"))
+
+		lines := strings.Split(output, "\n")
+		for _, line := range lines {
+			if strings.HasPrefix(line, "lines, err") || strings.HasPrefix(line, "|") || strings.HasPrefix(line, "[typeset]") ||
+				strings.HasPrefix(line, "https://") {
+				continue
+			}
+
+			require.LessOrEqualf(t, len(line), 40, "expected line %q to be wrapped", line)
+		}
+
+		// prose is preserved, save for line breaks and quote prefixes
+		require.Equal(t, words(readme), words(output))
+
+		// untouched blocks
+		for _, untouched := range []string{
+			"# go-typeset\n",
+			"```go\nlines, err := linebreak.New().Shaped(tokens, linebreak.AlignLeft, linebreak.UniformShape(30)) // a long line of code\n```\n",
+			"| Column | Description which is rather long, and should never be wrapped since this is a table |\n|--------|------|\n",
+			"\n[typeset]: https://github.com/fredbi/go-typeset/tree/master/docs/breaking-paragraphs-into-lines.pdf\n",
+			"`linebreak.New()`",
+		} {
+			require.Contains(t, output, untouched)
+		}
+
+		// formatting is idempotent
+		again, err := New(40).FormatString(output)
+		require.NoError(t, err)
+		require.Equal(t, output, again)
+	})
+
+	t.Run("should render list markers as hanging indents", func(t *testing.T) {
+		lines, err := New(30).FormatLines([]string{
+			"- a line breaker, which wraps words nicely under width and alignment constraints",
+			"10. an ordered item, which is long enough to be wrapped",
+		})
+		require.NoError(t, err)
+		require.Equal(t, []string{
+			"- a line breaker, which wraps",
+			"  words nicely under width and",
+			"  alignment constraints",
+			"10. an ordered item, which is",
+			"    long enough to be wrapped",
+		}, lines)
+	})
+
+	t.Run("should render block quotes", func(t *testing.T) {
+		lines, err := New(20).FormatLines([]string{
+			"> A quote with a first paragraph.",
+			">",
+			"> And a second one.",
+		})
+		require.NoError(t, err)
+		require.Equal(t, []string{
+			"> A quote with a",
+			"> first paragraph.",
+			">",
+			"> And a second one.",
+		}, lines)
+	})
+
+	t.Run("should not start blocks at the beginning of lines", func(t *testing.T) {
+		lines, err := New(12).FormatLines([]string{
+			"one two three - four # five 1. six",
+		})
+		require.NoError(t, err)
+
+		blocks := Parse(lines)
+		require.Len(t, blocks, 1)
+		require.Equal(t, KindParagraph, blocks[0].Kind)
+	})
+
+	t.Run("should never break code spans", func(t *testing.T) {
+		lines, err := New(10).FormatLines([]string{
+			"call ``lb.Shaped(tokens, align, shape)`` to wrap",
+		})
+		require.NoError(t, err)
+		require.Contains(t, lines, "``lb.Shaped(tokens, align, shape)``")
+	})
+
+	t.Run("should preserve hard line breaks", func(t *testing.T) {
+		lines, err := New(80).FormatLines([]string{
+			"first line\\",
+			"second line  ",
+			"third",
+			"line",
+		})
+		require.NoError(t, err)
+		require.Equal(t, []string{"first line\\", "second line  ", "third line"}, lines)
+	})
+
+	t.Run("should fail when nested blocks are too narrow", func(t *testing.T) {
+		_, err := New(4).FormatLines([]string{"> > > text"})
+		require.ErrorIs(t, err, ErrTooNarrow)
+	})
+}
+
+func words(text string) []string {
+	var result []string
+	for _, field := range strings.Fields(text) {
+		if field != ">" {
+			result = append(result, field)
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,49 @@
+package markdown
+
+import (
+	"github.com/fredbi/go-typeset/linebreak"
+)
+
+type (
+	// Option configures a Formatter.
+	Option func(*options)
+
+	options struct {
+		lb *linebreak.LineBreaker
+	}
+)
+
+// WithLineBreaker sets the line breaker used to wrap paragraphs.
+//
+// The default is a linebreak.LineBreaker which never breaks words, neither with hyphens nor after
+// punctuation marks, and allows overfull lines whenever a word is wider than the available width.
+//
+// A custom line breaker should not break words either, since any hyphen it inserts would be rendered.
+func WithLineBreaker(lb *linebreak.LineBreaker) Option {
+	return func(o *options) {
+		o.lb = lb
+	}
+}
+
+func defaultOptions(opts []Option) *options {
+	o := &options{}
+
+	for _, apply := range opts {
+		apply(o)
+	}
+
+	if o.lb == nil {
+		o.lb = linebreak.New(
+			linebreak.WithWordBreak(false),
+			linebreak.WithPunctuator(wholeWord),
+			linebreak.WithOverfull(true),
+		)
+	}
+
+	return o
+}
+
+// wholeWord is a punctuator which does not split words.
+func wholeWord(word []rune) [][]rune {
+	return [][]rune{word}
+}
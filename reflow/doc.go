@@ -0,0 +1,14 @@
+// Package reflow re-wraps blocks of source code comments.
+//
+// The comment leader of every line (e.g. "//", "#", "--", or " * " in a /* */ block) is stripped
+// and remembered, together with the indentation of the line. The prose found in consecutive lines
+// with the same leader is re-wrapped by a linebreak.LineBreaker, then emitted again with this leader.
+//
+// Lines which do not look like prose are preserved verbatim:
+//
+//   - lines that look like code, e.g. indented after the leader, within a ``` fence, or ending with "{", "}" or ";"
+//   - list items, e.g. starting with "- " or "1. "
+//   - lines with URLs
+//   - directives, e.g. "//go:generate" or "#!/bin/sh"
+//   - the opening and closing lines of /* */ blocks
+package reflow
@@ -0,0 +1,73 @@
+package reflow
+
+import (
+	"github.com/fredbi/go-typeset/linebreak"
+)
+
+type (
+	// Option configures a Reflower.
+	Option func(*options)
+
+	options struct {
+		tabWidth int
+		leaders  []string
+		lb       *linebreak.LineBreaker
+	}
+)
+
+// WithTabWidth sets the width of a tab in the indentation of comments.
+//
+// The default is 4.
+func WithTabWidth(width int) Option {
+	return func(o *options) {
+		o.tabWidth = width
+	}
+}
+
+// WithLeaders sets the comment leaders recognized at the start of lines.
+//
+// Leaders are tried in order: a leader which is the prefix of another one must come last.
+//
+// The default is "//", "--", "#", "*".
+func WithLeaders(leaders ...string) Option {
+	return func(o *options) {
+		o.leaders = leaders
+	}
+}
+
+// WithLineBreaker sets the line breaker used to re-wrap the prose in comments.
+//
+// The default is a linebreak.LineBreaker which never breaks words, neither with hyphens nor after
+// punctuation marks (e.g. identifiers such as "linebreak.New" are kept whole), and allows overfull
+// lines whenever a word is wider than the available width.
+func WithLineBreaker(lb *linebreak.LineBreaker) Option {
+	return func(o *options) {
+		o.lb = lb
+	}
+}
+
+func defaultOptions(opts []Option) *options {
+	o := &options{
+		tabWidth: 4,
+		leaders:  []string{"//", "--", "#", "*"},
+	}
+
+	for _, apply := range opts {
+		apply(o)
+	}
+
+	if o.lb == nil {
+		o.lb = linebreak.New(
+			linebreak.WithWordBreak(false),
+			linebreak.WithPunctuator(wholeWord),
+			linebreak.WithOverfull(true),
+		)
+	}
+
+	return o
+}
+
+// wholeWord is a punctuator which does not split words.
+func wholeWord(word []rune) [][]rune {
+	return [][]rune{word}
+}
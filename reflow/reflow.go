@@ -0,0 +1,254 @@
+package reflow
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/fredbi/go-typeset/linebreak"
+	"github.com/fredbi/go-typeset/terminal/runes"
+	"github.com/fredbi/go-typeset/wordbreak/tokenizer"
+)
+
+type (
+	// Reflower re-wraps blocks of source code comments within a maximum width.
+	Reflower struct {
+		width int
+		*options
+	}
+
+	// lineT is a line of comment, split into its prefix and its body.
+	lineT struct {
+		text     string // the original line
+		prefix   string // indentation and comment leader, with the space after the leader
+		body     string // the text of the comment
+		verbatim bool   // the line must be preserved as is
+	}
+
+	// paragraphT is a run of prose lines with the same prefix.
+	paragraphT struct {
+		prefix string
+		words  []string
+	}
+
+	err string
+)
+
+// ErrTooNarrow is returned when the width cannot hold any word after the comment leader.
+const ErrTooNarrow err = "the width is too narrow to fit text after the comment leader"
+
+// New Reflower, to re-wrap comments within some width, expressed in cells, leaders included.
+func New(width int, opts ...Option) *Reflower {
+	return &Reflower{
+		width:   width,
+		options: defaultOptions(opts),
+	}
+}
+
+// Reflow re-wraps the lines of a block of comments.
+func (r *Reflower) Reflow(lines []string) ([]string, error) {
+	result := make([]string, 0, len(lines))
+	var (
+		paragraph paragraphT
+		fenced    bool
+	)
+
+	flush := func() error {
+		if len(paragraph.words) == 0 {
+			return nil
+		}
+
+		wrapped, err := r.wrap(paragraph)
+		if err != nil {
+			return err
+		}
+
+		result = append(result, wrapped...)
+		paragraph = paragraphT{}
+
+		return nil
+	}
+
+	for _, text := range lines {
+		line := r.splitLine(text)
+
+		if isFence(line.body) {
+			fenced = !fenced
+			line.verbatim = true
+		}
+
+		if fenced || line.verbatim || line.body == "" || looksVerbatim(line.body) {
+			if err := flush(); err != nil {
+				return nil, err
+			}
+
+			result = append(result, strings.TrimRightFunc(line.text, unicode.IsSpace))
+
+			continue
+		}
+
+		if line.prefix != paragraph.prefix {
+			if err := flush(); err != nil {
+				return nil, err
+			}
+
+			paragraph.prefix = line.prefix
+		}
+
+		paragraph.words = append(paragraph.words, strings.FieldsFunc(line.body, tokenizer.IsSeparator)...)
+	}
+
+	if err := flush(); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// ReflowString re-wraps a block of comments, with lines separated by "\n".
+func (r *Reflower) ReflowString(text string) (string, error) {
+	trailing := strings.HasSuffix(text, "\n")
+	lines, err := r.Reflow(strings.Split(strings.TrimSuffix(text, "\n"), "\n"))
+	if err != nil {
+		return "", err
+	}
+
+	result := strings.Join(lines, "\n")
+	if trailing {
+		result += "\n"
+	}
+
+	return result, nil
+}
+
+// wrap the words of a paragraph, and emit them with the prefix of the paragraph.
+func (r *Reflower) wrap(paragraph paragraphT) ([]string, error) {
+	width := r.width - r.prefixWidth(paragraph.prefix)
+	if width < 1 {
+		return nil, ErrTooNarrow
+	}
+
+	lines, err := r.lb.Shaped(paragraph.words, linebreak.AlignLeft, linebreak.UniformShape(float64(width)))
+	if err != nil {
+		return nil, err
+	}
+
+	for i, line := range lines {
+		lines[i] = strings.TrimRightFunc(paragraph.prefix+line, unicode.IsSpace)
+	}
+
+	return lines, nil
+}
+
+// splitLine splits a line into its indentation and comment leader, and the text of the comment.
+func (r *Reflower) splitLine(text string) lineT {
+	trimmed := strings.TrimLeft(text, " \t")
+	indent := text[:len(text)-len(trimmed)]
+	line := lineT{
+		text:   text,
+		prefix: indent,
+		body:   strings.TrimRightFunc(trimmed, unicode.IsSpace),
+	}
+
+	if strings.HasPrefix(trimmed, "/*") || strings.HasSuffix(line.body, "*/") {
+		// opening or closing line of a block comment
+		line.verbatim = true
+
+		return line
+	}
+
+	for _, leader := range r.leaders {
+		if !strings.HasPrefix(trimmed, leader) {
+			continue
+		}
+
+		after := strings.TrimRightFunc(trimmed[len(leader):], unicode.IsSpace)
+		switch {
+		case after == "":
+			// blank comment line
+			line.prefix = indent + leader
+			line.body = ""
+		case strings.HasPrefix(after, " "):
+			line.prefix = indent + leader + " "
+			line.body = after[1:]
+		default:
+			// a directive, such as "//go:generate", or a decorative line, such as "#####"
+			line.prefix = indent + leader
+			line.body = after
+			line.verbatim = true
+		}
+
+		return line
+	}
+
+	return line
+}
+
+// prefixWidth measures the width of a prefix, with tabs expanded.
+func (r *Reflower) prefixWidth(prefix string) int {
+	var width int
+	for _, rn := range prefix {
+		if rn == '\t' {
+			width += r.tabWidth - width%r.tabWidth
+
+			continue
+		}
+
+		width += runes.Width(rn)
+	}
+
+	return width
+}
+
+// looksVerbatim tells if the text of a comment looks like code, a list item or a URL,
+// rather than prose.
+func looksVerbatim(body string) bool {
+	switch {
+	case strings.HasPrefix(body, " ") || strings.HasPrefix(body, "\t"):
+		// indented code
+		return true
+	case isListItem(body):
+		return true
+	case strings.Contains(body, "://"):
+		// URL
+		return true
+	case strings.HasSuffix(body, "{") || strings.HasSuffix(body, "}") || strings.HasSuffix(body, ";"):
+		return true
+	case strings.Contains(body, ":=") || strings.Contains(body, "==") || strings.Contains(body, "!="):
+		return true
+	default:
+		return false
+	}
+}
+
+// isListItem tells if the text of a comment starts with a bullet ("-", "*", "+", "•") or a number ("1.", "2)").
+func isListItem(body string) bool {
+	marker, _, found := strings.Cut(body, " ")
+	if !found {
+		return false
+	}
+
+	switch marker {
+	case "-", "*", "+", "•":
+		return true
+	}
+
+	if len(marker) < 2 || !strings.ContainsAny(marker[len(marker)-1:], ".)") {
+		return false
+	}
+
+	for _, rn := range marker[:len(marker)-1] {
+		if !unicode.IsDigit(rn) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func isFence(body string) bool {
+	return strings.HasPrefix(body, "```")
+}
+
+func (e err) Error() string {
+	return string(e)
+}
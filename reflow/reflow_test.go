@@ -0,0 +1,122 @@
+package reflow
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReflow(t *testing.T) {
+	t.Run("should re-wrap Go comments", func(t *testing.T) {
+		const input = `	// Reflow re-wraps the lines of a block of comments. The leader of every line is
+	// stripped and remembered.
+	// The prose is re-wrapped, and emitted again with the same leaders, so that linebreak.New is never broken.
+`
+		output, err := New(40).ReflowString(input)
+		require.NoError(t, err)
+		require.True(t, strings.HasSuffix(output, "\n"))
+
+		lines := strings.Split(strings.TrimSuffix(output, "\n"), "\n")
+		require.Greater(t, len(lines), 3)
+		for _, line := range lines {
+			require.True(t, strings.HasPrefix(line, "\t// "))
+			require.LessOrEqual(t, len(line)+3, 40) // a tab is 4 cells wide
+		}
+
+		require.Equal(t, words(input), words(output))
+		require.Contains(t, output, "linebreak.New")
+	})
+
+	t.Run("should preserve blank lines between paragraphs", func(t *testing.T) {
+		lines, err := New(30).Reflow([]string{
+			"# A first paragraph, with",
+			"# several words.",
+			"#",
+			"# A second paragraph.",
+		})
+		require.NoError(t, err)
+		require.Equal(t, []string{
+			"# A first paragraph, with",
+			"# several words.",
+			"#",
+			"# A second paragraph.",
+		}, lines)
+	})
+
+	t.Run("should join short lines", func(t *testing.T) {
+		lines, err := New(80).Reflow([]string{
+			"-- Short",
+			"-- lines",
+			"-- are joined.",
+		})
+		require.NoError(t, err)
+		require.Equal(t, []string{"-- Short lines are joined."}, lines)
+	})
+
+	t.Run("should re-wrap block comments", func(t *testing.T) {
+		lines, err := New(24).Reflow([]string{
+			"/*",
+			" * The leader of lines in a block comment is a star.",
+			" */",
+		})
+		require.NoError(t, err)
+		require.Equal(t, []string{
+			"/*",
+			" * The leader of lines",
+			" * in a block comment is",
+			" * a star.",
+			" */",
+		}, lines)
+	})
+
+	t.Run("should preserve code, lists, URLs and directives", func(t *testing.T) {
+		input := []string{
+			"//go:generate go run ./gen",
+			"// Example:",
+			"//",
+			"//	lb := linebreak.New()",
+			"//	lines, err := lb.Shaped(tokens, linebreak.AlignLeft, shape)",
+			"//",
+			"// Features:",
+			"//   - first item, which is long enough to be wrapped if it were prose",
+			"//   - second item",
+			"// 1. numbered item, which is long enough to be wrapped if it were prose",
+			"// See https://github.com/fredbi/go-typeset/tree/master/docs/breaking-paragraphs-into-lines.pdf",
+			"// if x == nil {",
+			"// ```",
+			"// a fenced block, which is long enough to be wrapped if it were prose",
+			"// ```",
+		}
+		lines, err := New(30).Reflow(input)
+		require.NoError(t, err)
+		require.Equal(t, input, lines)
+	})
+
+	t.Run("should not mix paragraphs with different leaders", func(t *testing.T) {
+		lines, err := New(80).Reflow([]string{
+			"// one",
+			"// two",
+			"  // three",
+			"  // four",
+		})
+		require.NoError(t, err)
+		require.Equal(t, []string{"// one two", "  // three four"}, lines)
+	})
+
+	t.Run("should fail when the leader is too wide", func(t *testing.T) {
+		_, err := New(3).Reflow([]string{"    // some text"})
+		require.ErrorIs(t, err, ErrTooNarrow)
+	})
+}
+
+func words(text string) []string {
+	var result []string
+	for _, field := range strings.Fields(text) {
+		if field != "//" {
+			result = append(result, field)
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,11 @@
+// Package table renders tables on a terminal, with text wrapped within cells.
+//
+// The text of every cell is set by a linebreak.LineBreaker, and measured with runes.Widths,
+// so that columns with East-Asian characters or emojis line up. ANSI escape sequences in cells
+// are not accounted for in widths, and are closed at the end of every line in a cell.
+//
+// Column widths are computed to fit a total width budget, with the automatic table layout
+// algorithm used by web browsers: every column gets at least the width of its widest word,
+// and the remaining space is distributed in proportion to the extra width the column would need
+// to set every cell on a single line.
+package table
@@ -0,0 +1,79 @@
+package table
+
+import (
+	"github.com/fredbi/go-typeset/linebreak"
+)
+
+type (
+	// Option configures a Table.
+	Option func(*options)
+
+	options struct {
+		style  Style
+		align  linebreak.Alignment
+		aligns []linebreak.Alignment
+		lb     *linebreak.LineBreaker
+	}
+)
+
+// WithStyle sets the style of borders.
+//
+// The default is StylePlain.
+func WithStyle(style Style) Option {
+	return func(o *options) {
+		o.style = style
+	}
+}
+
+// WithAlignment sets the default alignment of cells.
+//
+// The default is linebreak.AlignLeft.
+func WithAlignment(align linebreak.Alignment) Option {
+	return func(o *options) {
+		o.align = align
+	}
+}
+
+// WithColumnAlignments sets the alignment of cells, column by column.
+//
+// Columns beyond the provided alignments use the default alignment.
+func WithColumnAlignments(aligns ...linebreak.Alignment) Option {
+	return func(o *options) {
+		o.aligns = aligns
+	}
+}
+
+// WithLineBreaker sets the line breaker used to wrap text within cells.
+//
+// The default is a linebreak.LineBreaker with the WithForceBreak option,
+// so that cells can always be set, even in very narrow columns.
+func WithLineBreaker(lb *linebreak.LineBreaker) Option {
+	return func(o *options) {
+		o.lb = lb
+	}
+}
+
+func defaultOptions(opts []Option) *options {
+	o := &options{
+		style: StylePlain,
+		align: linebreak.AlignLeft,
+	}
+
+	for _, apply := range opts {
+		apply(o)
+	}
+
+	if o.lb == nil {
+		o.lb = linebreak.New(linebreak.WithForceBreak(true))
+	}
+
+	return o
+}
+
+func (o *options) alignment(column int) linebreak.Alignment {
+	if column < len(o.aligns) {
+		return o.aligns[column]
+	}
+
+	return o.align
+}
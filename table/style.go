@@ -0,0 +1,65 @@
+package table
+
+type (
+	// Style of the borders of a table.
+	Style uint8
+
+	// bordersT holds the runes to draw the borders of a table.
+	bordersT struct {
+		outer      bool // draw outer borders
+		vertical   rune
+		horizontal rune
+		top        [3]rune // left, middle, right
+		middle     [3]rune
+		bottom     [3]rune
+	}
+)
+
+const (
+	// StylePlain renders columns separated by spaces, with the header underlined.
+	StylePlain Style = iota
+
+	// StyleASCII renders borders with ASCII characters.
+	StyleASCII
+
+	// StyleBox renders borders with unicode box-drawing characters.
+	StyleBox
+)
+
+func (s Style) borders() bordersT {
+	switch s {
+	case StyleASCII:
+		return bordersT{
+			outer:      true,
+			vertical:   '|',
+			horizontal: '-',
+			top:        [3]rune{'+', '+', '+'},
+			middle:     [3]rune{'+', '+', '+'},
+			bottom:     [3]rune{'+', '+', '+'},
+		}
+	case StyleBox:
+		return bordersT{
+			outer:      true,
+			vertical:   '│',
+			horizontal: '─',
+			top:        [3]rune{'┌', '┬', '┐'},
+			middle:     [3]rune{'├', '┼', '┤'},
+			bottom:     [3]rune{'└', '┴', '┘'},
+		}
+	default:
+		return bordersT{
+			vertical:   ' ',
+			horizontal: '─',
+			middle:     [3]rune{' ', ' ', ' '},
+		}
+	}
+}
+
+// overhead yields the width taken by borders and padding, for a number of columns.
+func (b bordersT) overhead(columns int) int {
+	if b.outer {
+		return 3*columns + 1
+	}
+
+	return 3 * (columns - 1)
+}
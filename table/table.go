@@ -0,0 +1,247 @@
+package table
+
+import (
+	"strings"
+
+	"github.com/fredbi/go-typeset/linebreak"
+	"github.com/fredbi/go-typeset/terminal/ansi"
+	"github.com/fredbi/go-typeset/terminal/runes"
+	"github.com/fredbi/go-typeset/wordbreak/tokenizer"
+)
+
+type (
+	// Table renders rows of cells on a terminal, with text wrapped within cells.
+	Table struct {
+		header [][][]rune // tokens for every cell in the header
+		rows   [][][][]rune
+		*options
+	}
+
+	// cellT is a cell set within the width of its column.
+	cellT struct {
+		lines [][]rune
+	}
+
+	err string
+)
+
+// ErrTooNarrow is returned when the width budget cannot hold the widest rune of every column.
+const ErrTooNarrow err = "the table is too narrow to render all columns"
+
+// New table.
+func New(opts ...Option) *Table {
+	return &Table{
+		options: defaultOptions(opts),
+	}
+}
+
+// SetHeader sets the cells of the header of the table.
+func (t *Table) SetHeader(cells ...string) {
+	t.header = tokenize(cells)
+}
+
+// AddRow adds a row of cells to the table.
+//
+// Rows may have different numbers of cells: missing cells are rendered empty.
+func (t *Table) AddRow(cells ...string) {
+	t.rows = append(t.rows, tokenize(cells))
+}
+
+// Render the table within a total width, expressed in cells, borders included.
+func (t *Table) Render(width int) ([]string, error) {
+	columns := t.columns()
+	if columns == 0 {
+		return nil, nil
+	}
+
+	borders := t.style.borders()
+	widths, err := columnWidths(t.allRows(), columns, width-borders.overhead(columns))
+	if err != nil {
+		return nil, err
+	}
+
+	lines := make([]string, 0, 2*len(t.rows)+4)
+	if borders.outer {
+		lines = append(lines, borders.rule(widths, borders.top))
+	}
+
+	if len(t.header) > 0 {
+		header, err := t.setRow(t.header, widths)
+		if err != nil {
+			return nil, err
+		}
+
+		lines = append(lines, borders.row(header, widths)...)
+		lines = append(lines, borders.rule(widths, borders.middle))
+	}
+
+	for _, cells := range t.rows {
+		row, err := t.setRow(cells, widths)
+		if err != nil {
+			return nil, err
+		}
+
+		lines = append(lines, borders.row(row, widths)...)
+	}
+
+	if borders.outer {
+		lines = append(lines, borders.rule(widths, borders.bottom))
+	}
+
+	return lines, nil
+}
+
+func (t *Table) columns() int {
+	var columns int
+	for _, row := range t.allRows() {
+		if len(row) > columns {
+			columns = len(row)
+		}
+	}
+
+	return columns
+}
+
+func (t *Table) allRows() [][][][]rune {
+	if len(t.header) == 0 {
+		return t.rows
+	}
+
+	return append([][][][]rune{t.header}, t.rows...)
+}
+
+// setRow wraps the text of every cell in a row, within the width of its column.
+func (t *Table) setRow(cells [][][]rune, widths []int) ([]cellT, error) {
+	row := make([]cellT, len(widths))
+
+	for j, tokens := range cells {
+		if len(tokens) == 0 {
+			continue
+		}
+
+		lines, err := t.lb.ShapedRunes(tokens, t.alignment(j), linebreak.UniformShape(float64(widths[j])))
+		if err != nil {
+			return nil, err
+		}
+
+		row[j] = cellT{lines: trimEmptyLines(lines)}
+	}
+
+	return row, nil
+}
+
+// trimEmptyLines removes the trailing empty lines of a cell, so they don't render as extra rows.
+func trimEmptyLines(lines [][]rune) [][]rune {
+	for len(lines) > 0 && len(strings.TrimSpace(string(ansi.Strip(lines[len(lines)-1])))) == 0 {
+		lines = lines[:len(lines)-1]
+	}
+
+	return lines
+}
+
+// row renders the lines of a row of cells, with borders.
+func (b bordersT) row(cells []cellT, widths []int) []string {
+	height := 1 // a row of empty cells still renders as a blank line
+	for _, cell := range cells {
+		if len(cell.lines) > height {
+			height = len(cell.lines)
+		}
+	}
+
+	lines := make([]string, 0, height)
+	var line strings.Builder
+
+	for i := 0; i < height; i++ {
+		line.Reset()
+
+		if b.outer {
+			line.WriteRune(b.vertical)
+			line.WriteRune(' ')
+		}
+
+		for j, cell := range cells {
+			if j > 0 {
+				line.WriteRune(' ')
+				line.WriteRune(b.vertical)
+				line.WriteRune(' ')
+			}
+
+			var text []rune
+			if i < len(cell.lines) {
+				text = cell.lines[i]
+			}
+
+			line.WriteString(string(text))
+			line.WriteString(strings.Repeat(" ", max(widths[j]-visibleWidth(text), 0)))
+		}
+
+		if b.outer {
+			line.WriteRune(' ')
+			line.WriteRune(b.vertical)
+		}
+
+		if b.outer {
+			lines = append(lines, line.String())
+		} else {
+			lines = append(lines, strings.TrimRight(line.String(), " "))
+		}
+	}
+
+	return lines
+}
+
+// rule renders a horizontal line, with junctions.
+func (b bordersT) rule(widths []int, junctions [3]rune) string {
+	var line strings.Builder
+	padding := 0
+	if b.outer {
+		padding = 2
+		line.WriteRune(junctions[0])
+	}
+
+	for j, width := range widths {
+		if j > 0 {
+			if b.outer {
+				line.WriteRune(junctions[1])
+			} else {
+				line.WriteString("   ")
+			}
+		}
+
+		line.WriteString(strings.Repeat(string(b.horizontal), width+padding))
+	}
+
+	if b.outer {
+		line.WriteRune(junctions[2])
+	}
+
+	return line.String()
+}
+
+func tokenize(cells []string) [][][]rune {
+	t := tokenizer.New()
+	row := make([][][]rune, 0, len(cells))
+
+	for _, cell := range cells {
+		row = append(row, t.BreakWordString(cell))
+	}
+
+	return row
+}
+
+// visibleWidth measures the width of some text on a terminal, ignoring ANSI escape sequences.
+func visibleWidth(text []rune) int {
+	return runes.Widths(ansi.Strip(text))
+}
+
+func (e err) Error() string {
+	return string(e)
+}
+
+func max(a, b int) int {
+	if a > b {
+		return a
+	}
+
+	return b
+}
@@ -0,0 +1,222 @@
+package table
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/fredbi/go-typeset/linebreak"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTable(t *testing.T) {
+	t.Run("should render a plain table with natural widths", func(t *testing.T) {
+		tbl := New()
+		tbl.SetHeader("Name", "Value")
+		tbl.AddRow("alpha", "1")
+		tbl.AddRow("beta gamma", "22")
+
+		lines, err := tbl.Render(80)
+		require.NoError(t, err)
+		require.Equal(t, []string{
+			"Name         Value",
+			"──────────   ─────",
+			"alpha        1",
+			"beta gamma   22",
+		}, lines)
+	})
+
+	t.Run("should render a box table", func(t *testing.T) {
+		tbl := New(WithStyle(StyleBox), WithColumnAlignments(linebreak.AlignLeft, linebreak.AlignRight))
+		tbl.SetHeader("Name", "Value")
+		tbl.AddRow("alpha", "1")
+
+		lines, err := tbl.Render(80)
+		require.NoError(t, err)
+		require.Equal(t, []string{
+			"┌───────┬───────┐",
+			"│ Name  │ Value │",
+			"├───────┼───────┤",
+			"│ alpha │     1 │",
+			"└───────┴───────┘",
+		}, lines)
+	})
+
+	t.Run("should wrap cells within a width budget", func(t *testing.T) {
+		const (
+			width = 50
+			text  = `In olden times when wishing still helped one, there lived a king ` +
+				`whose daughters were all beautiful, but the youngest was so beautiful ` +
+				`that the sun itself, which has seen so much, was astonished whenever it shone in her face.`
+		)
+
+		for _, align := range []linebreak.Alignment{linebreak.AlignLeft, linebreak.AlignJustify, linebreak.AlignCenter, linebreak.AlignRight} {
+			tbl := New(WithStyle(StyleASCII), WithAlignment(align))
+			tbl.SetHeader("Key", "Description", "Notes")
+			tbl.AddRow("frog", text, "a short note")
+			tbl.AddRow("king", "short", text)
+
+			lines, err := tbl.Render(width)
+			require.NoError(t, err)
+			require.Greater(t, len(lines), 10)
+
+			for _, line := range lines {
+				require.Equalf(t, width, visibleWidth([]rune(line)), "expected line %q to fill the width of the table", line)
+				require.True(t, strings.HasPrefix(line, "|") || strings.HasPrefix(line, "+"))
+			}
+		}
+	})
+
+	t.Run("should line up wide runes", func(t *testing.T) {
+		tbl := New(WithStyle(StyleBox))
+		tbl.SetHeader("語", "emoji")
+		tbl.AddRow("日本語のテキスト", "🏈 🏈 🏈")
+		tbl.AddRow("abc", "x")
+
+		lines, err := tbl.Render(80)
+		require.NoError(t, err)
+
+		for _, line := range lines {
+			require.Equalf(t, visibleWidth([]rune(lines[0])), visibleWidth([]rune(line)), "misaligned line %q", line)
+		}
+		require.Equal(t, "│ 日本語のテキスト │ 🏈 🏈 🏈 │", lines[3])
+	})
+
+	t.Run("should render colored cells", func(t *testing.T) {
+		const startRed, stop = "\033[31m", "\033[0m"
+
+		tbl := New(WithStyle(StyleBox))
+		tbl.AddRow(startRed+"some red text to be wrapped"+stop, "plain")
+
+		lines, err := tbl.Render(20)
+		require.NoError(t, err)
+
+		for _, line := range lines {
+			require.Equalf(t, 20, visibleWidth([]rune(line)), "misaligned line %q", line)
+			require.Equal(t, strings.Count(line, startRed), strings.Count(line, stop))
+		}
+		require.Contains(t, lines[2], startRed)
+	})
+
+	t.Run("should break words in very narrow columns", func(t *testing.T) {
+		tbl := New()
+		tbl.AddRow("extraordinarily", "x")
+
+		lines, err := tbl.Render(10)
+		require.NoError(t, err)
+		for _, line := range lines {
+			require.LessOrEqual(t, visibleWidth([]rune(line)), 10)
+		}
+	})
+
+	t.Run("should fit every row in narrow box tables", func(t *testing.T) {
+		const text = `In olden times when wishing still helped one, there lived a king ` +
+			`whose daughters were all beautiful, but the youngest was so beautiful.`
+
+		for _, width := range []int{30, 14} {
+			for _, align := range []linebreak.Alignment{linebreak.AlignLeft, linebreak.AlignJustify, linebreak.AlignCenter, linebreak.AlignRight} {
+				tbl := New(WithStyle(StyleBox), WithAlignment(align))
+				tbl.SetHeader("Key", "Description", "Notes")
+				tbl.AddRow("frog", text, "a short note")
+				tbl.AddRow("king", "short", text)
+
+				lines, err := tbl.Render(width)
+				require.NoError(t, err)
+				require.True(t, strings.HasPrefix(lines[1], "│ K"), "expected the header to be the first row")
+
+				for _, line := range lines {
+					require.Equalf(t, width, visibleWidth([]rune(line)),
+						"expected line %q to fill the width of the table (alignment: %v)", line, align,
+					)
+					require.NotEqualf(t, "", strings.Trim(line, "│ "), "unexpected blank row (width: %d, alignment: %v)", width, align)
+				}
+			}
+		}
+	})
+
+	t.Run("should fit wide runes in narrow columns", func(t *testing.T) {
+		for _, width := range []int{16, 20, 30} {
+			tbl := New(WithStyle(StyleBox))
+			tbl.SetHeader("語", "emoji")
+			tbl.AddRow("日本語のテキスト", "🏈 🏈 🏈")
+			tbl.AddRow("abc", "x")
+
+			lines, err := tbl.Render(width)
+			require.NoError(t, err)
+
+			for _, line := range lines {
+				require.Equalf(t, width, visibleWidth([]rune(line)), "misaligned line %q", line)
+			}
+		}
+
+		tbl := New(WithStyle(StyleBox))
+		tbl.AddRow("日本語", "🏈")
+
+		_, err := tbl.Render(10)
+		require.ErrorIs(t, err, ErrTooNarrow)
+	})
+
+	t.Run("should not render a table which is too narrow", func(t *testing.T) {
+		tbl := New(WithStyle(StyleBox))
+		tbl.AddRow("a", "b", "c")
+
+		_, err := tbl.Render(9)
+		require.ErrorIs(t, err, ErrTooNarrow)
+	})
+
+	t.Run("should render a row of empty cells", func(t *testing.T) {
+		tbl := New(WithStyle(StyleBox))
+		tbl.SetHeader("Name", "Value")
+		tbl.AddRow("", "")
+		tbl.AddRow("alpha", "1")
+
+		lines, err := tbl.Render(80)
+		require.NoError(t, err)
+		require.Equal(t, []string{
+			"┌───────┬───────┐",
+			"│ Name  │ Value │",
+			"├───────┼───────┤",
+			"│       │       │",
+			"│ alpha │ 1     │",
+			"└───────┴───────┘",
+		}, lines)
+	})
+
+	t.Run("should render an empty table", func(t *testing.T) {
+		lines, err := New().Render(80)
+		require.NoError(t, err)
+		require.Empty(t, lines)
+	})
+}
+
+func TestColumnWidths(t *testing.T) {
+	rows := [][][][]rune{
+		tokenize([]string{"a bb", "ccc dddd eeeee"}),
+	}
+
+	t.Run("should use natural widths", func(t *testing.T) {
+		widths, err := columnWidths(rows, 2, 100)
+		require.NoError(t, err)
+		require.Equal(t, []int{4, 14}, widths)
+	})
+
+	t.Run("should distribute the budget", func(t *testing.T) {
+		widths, err := columnWidths(rows, 2, 12)
+		require.NoError(t, err)
+		require.Equal(t, []int{3, 9}, widths)
+	})
+
+	t.Run("should shrink below the widest words", func(t *testing.T) {
+		widths, err := columnWidths(rows, 2, 5)
+		require.NoError(t, err)
+		require.Equal(t, []int{2, 3}, widths)
+	})
+
+	t.Run("should not shrink below the widest runes", func(t *testing.T) {
+		widths, err := columnWidths([][][][]rune{tokenize([]string{"日本語", "a bb"})}, 2, 4)
+		require.NoError(t, err)
+		require.Equal(t, []int{3, 1}, widths)
+
+		_, err = columnWidths([][][][]rune{tokenize([]string{"日本語", "🏈"})}, 2, 3)
+		require.ErrorIs(t, err, ErrTooNarrow)
+	})
+}
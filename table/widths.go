@@ -0,0 +1,125 @@
+package table
+
+import (
+	"github.com/fredbi/go-typeset/terminal/ansi"
+	"github.com/fredbi/go-typeset/terminal/runes"
+)
+
+// columnWidths computes the width of every column to fit a budget.
+//
+// Every column gets at least the width of its widest word, then the remaining budget is distributed
+// in proportion to the extra width needed to set every cell of the column on a single line.
+//
+// Whenever the budget cannot even hold the widest words, it is distributed in proportion to these, and
+// words are broken. A column is never narrower than its widest rune, which cannot be broken.
+func columnWidths(rows [][][][]rune, columns, budget int) ([]int, error) {
+	runeWidths := make([]int, columns)
+	minWidths := make([]int, columns)
+	maxWidths := make([]int, columns)
+	for j := range minWidths {
+		runeWidths[j] = 1
+	}
+
+	for _, row := range rows {
+		for j, tokens := range row {
+			natural := -1
+			for _, token := range tokens {
+				width := visibleWidth(token)
+				runeWidths[j] = max(runeWidths[j], widestRune(token))
+				minWidths[j] = max(minWidths[j], width)
+				natural += width + 1
+			}
+
+			maxWidths[j] = max(maxWidths[j], natural)
+		}
+	}
+
+	for j := range minWidths {
+		minWidths[j] = max(minWidths[j], runeWidths[j])
+		maxWidths[j] = max(maxWidths[j], minWidths[j])
+	}
+
+	sumRunes, sumMin, sumMax := sum(runeWidths), sum(minWidths), sum(maxWidths)
+
+	switch {
+	case sumRunes > budget:
+		return nil, ErrTooNarrow
+
+	case sumMax <= budget:
+		return maxWidths, nil
+
+	case sumMin <= budget:
+		extra := make([]int, columns)
+		for j := range extra {
+			extra[j] = maxWidths[j] - minWidths[j]
+		}
+
+		return addWidths(minWidths, distribute(budget-sumMin, extra)), nil
+
+	default:
+		shrunk := make([]int, columns)
+		for j := range shrunk {
+			shrunk[j] = minWidths[j] - runeWidths[j]
+		}
+
+		return addWidths(runeWidths, distribute(budget-sumRunes, shrunk)), nil
+	}
+}
+
+// widestRune yields the width of the widest rune in a token, ignoring ANSI escape sequences.
+func widestRune(token []rune) int {
+	var widest int
+	for _, r := range ansi.Strip(token) {
+		widest = max(widest, runes.Width(r))
+	}
+
+	return widest
+}
+
+// distribute an amount in proportion to weights, with largest remainders rounding.
+func distribute(amount int, weights []int) []int {
+	total := sum(weights)
+	shares := make([]int, len(weights))
+	if total == 0 {
+		return shares
+	}
+
+	remainders := make([]int, len(weights))
+	distributed := 0
+	for j, weight := range weights {
+		shares[j] = amount * weight / total
+		remainders[j] = amount * weight % total
+		distributed += shares[j]
+	}
+
+	for ; distributed < amount; distributed++ {
+		largest := 0
+		for j := range remainders {
+			if remainders[j] > remainders[largest] {
+				largest = j
+			}
+		}
+
+		shares[largest]++
+		remainders[largest] = -1
+	}
+
+	return shares
+}
+
+func addWidths(a, b []int) []int {
+	for j := range a {
+		a[j] += b[j]
+	}
+
+	return a
+}
+
+func sum(values []int) int {
+	var total int
+	for _, value := range values {
+		total += value
+	}
+
+	return total
+}
@@ -45,6 +45,18 @@ func StripToken(token []rune) []StrippedToken {
 	return attrs
 }
 
+// Strip removes all start and stop ANSI escape sequences from a slice of runes.
+//
+// Other escape sequences are retained.
+func Strip(rns []rune) []rune {
+	stripped := make([]rune, 0, len(rns))
+	for _, token := range StripToken(rns) {
+		stripped = append(stripped, token.Text...)
+	}
+
+	return stripped
+}
+
 // StripANSIFromRunes strips a starting and a ending ANSI escape sequences from a token provided as a slice of runes.
 //
 // If the slice of runes contains several sequences, the remaining runes after the end of the first end sequence are returned.
@@ -386,3 +386,16 @@ func TestStripToken(t *testing.T) {
 		Remainder:     []rune{},
 	}, stripped[1])
 }
+
+func TestStrip(t *testing.T) {
+	t.Parallel()
+
+	t.Run("should leave non-escaped runes unchanged", func(t *testing.T) {
+		require.Equal(t, wordInput, string(Strip([]rune(wordInput))))
+	})
+
+	t.Run("should remove all start and stop sequences", func(t *testing.T) {
+		input := "a " + startInput + wordInput + endInput + " b " + startInput + "c" + endInput
+		require.Equal(t, "a "+wordInput+" b c", string(Strip([]rune(input))))
+	})
+}
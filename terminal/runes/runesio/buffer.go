@@ -0,0 +1,72 @@
+package runesio
+
+import (
+	"unicode/utf8"
+)
+
+var _ Writer = &Buffer{}
+
+// Buffer is a Writer that accumulates runes in a slice of runes.
+//
+// The zero value of a Buffer is ready to use.
+type Buffer struct {
+	runes []rune
+}
+
+// NewBuffer builds a Buffer with some preallocated capacity, expressed in runes.
+func NewBuffer(capacity int) *Buffer {
+	return &Buffer{
+		runes: make([]rune, 0, capacity),
+	}
+}
+
+// WriteRune appends a single rune to the buffer.
+//
+// The size written is expressed in bytes.
+func (b *Buffer) WriteRune(r rune) (int, error) {
+	b.runes = append(b.runes, r)
+
+	return utf8.RuneLen(r), nil
+}
+
+// WriteRunes appends runes to the buffer.
+//
+// The size written is expressed in bytes.
+func (b *Buffer) WriteRunes(runes []rune) (int, error) {
+	b.runes = append(b.runes, runes...)
+
+	var n int
+	for _, r := range runes {
+		n += utf8.RuneLen(r)
+	}
+
+	return n, nil
+}
+
+// Write appends UTF-8 encoded bytes to the buffer.
+//
+// Invalid UTF-8 sequences are decoded as utf8.RuneError.
+func (b *Buffer) Write(p []byte) (int, error) {
+	for i := 0; i < len(p); {
+		r, size := utf8.DecodeRune(p[i:])
+		b.runes = append(b.runes, r)
+		i += size
+	}
+
+	return len(p), nil
+}
+
+// Runes returns the accumulated runes.
+func (b *Buffer) Runes() []rune {
+	return b.runes
+}
+
+// Len returns the number of accumulated runes.
+func (b *Buffer) Len() int {
+	return len(b.runes)
+}
+
+// Reset the buffer, retaining the allocated memory.
+func (b *Buffer) Reset() {
+	b.runes = b.runes[:0]
+}
@@ -0,0 +1,42 @@
+package runesio
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuffer(t *testing.T) {
+	var b Buffer
+
+	t.Run("should write a single rune", func(t *testing.T) {
+		res, err := b.WriteRune('a')
+		require.NoError(t, err)
+		require.Equal(t, 1, res)
+		require.Equal(t, "a", string(b.Runes()))
+	})
+
+	t.Run("should write several runes", func(t *testing.T) {
+		res, err := b.WriteRunes([]rune{'é', '🏈'})
+		require.NoError(t, err)
+		require.Equal(t, 6, res)
+		require.Equal(t, "aé🏈", string(b.Runes()))
+		require.Equal(t, 3, b.Len())
+	})
+
+	t.Run("should write bytes", func(t *testing.T) {
+		res, err := b.Write([]byte("bç"))
+		require.NoError(t, err)
+		require.Equal(t, 3, res)
+		require.Equal(t, "aé🏈bç", string(b.Runes()))
+	})
+
+	t.Run("should reset", func(t *testing.T) {
+		b.Reset()
+		require.Empty(t, b.Runes())
+
+		buf := NewBuffer(10)
+		_, _ = buf.WriteRunes([]rune("abc"))
+		require.Equal(t, "abc", string(buf.Runes()))
+	})
+}
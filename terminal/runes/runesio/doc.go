@@ -2,4 +2,5 @@
 //
 // SliceReader implements io.RuneReader and io.Seeker.
 // The Writer knows how to write runes, like io.Write works with []byte.
+// The Buffer is a Writer that accumulates runes in memory.
 package runesio
@@ -1,6 +1,7 @@
 package hyphenator
 
 import (
+	"path"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -67,3 +68,20 @@ func BenchmarkLoadPatterns(b *testing.B) {
 		require.NotNil(b, dict)
 	}
 }
+
+func BenchmarkLoadCompiledPatterns(b *testing.B) {
+	supported, err := SupportedPatterns()
+	require.NoError(b, err)
+	compiled, err := texFS.ReadFile(path.Join(folder, CompiledName(supported[0])))
+	require.NoError(b, err)
+
+	b.ResetTimer()
+	b.ReportAllocs()
+	b.SetBytes(0)
+
+	for n := 0; n < b.N; n++ {
+		dict, err := LoadCompiledPatterns(compiled)
+		require.NoError(b, err)
+		require.NotNil(b, dict)
+	}
+}
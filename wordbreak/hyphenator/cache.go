@@ -1,6 +1,8 @@
 package hyphenator
 
-import "sync"
+import (
+	"sync"
+)
 
 var (
 	mx sync.Mutex
@@ -12,6 +14,15 @@ var (
 
 // load a preloaded trie Dictionary for some language patterns file.
 func loadDictFromCache(patterns string) *Dictionary {
+	return loadFromCache(patterns, func() (*Dictionary, error) {
+		return LoadPatterns(patterns)
+	})
+}
+
+// loadFromCache loads a Dictionary once for some key.
+//
+// Dictionaries which fail to load are not cached, and yield nil.
+func loadFromCache(key string, load func() (*Dictionary, error)) *Dictionary {
 	mx.Lock()
 	defer mx.Unlock()
 
@@ -19,12 +30,17 @@ func loadDictFromCache(patterns string) *Dictionary {
 		loadedPatterns = make(map[string]*Dictionary)
 	}
 
-	dict, ok := loadedPatterns[patterns]
-	if !ok {
-		dict, _ = LoadPatterns(patterns)
+	dict, ok := loadedPatterns[key]
+	if ok {
+		return dict
+	}
+
+	dict, err := load()
+	if err != nil {
+		return nil
 	}
 
-	loadedPatterns[patterns] = dict
+	loadedPatterns[key] = dict
 
 	return dict
 }
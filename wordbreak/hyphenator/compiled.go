@@ -0,0 +1,180 @@
+package hyphenator
+
+import (
+	"encoding/binary"
+	"strings"
+
+	"github.com/fredbi/go-typeset/wordbreak/hyphenator/internal/trie"
+)
+
+var _ patternsTrie = &trie.PackedTrie{}
+
+type (
+	// patternsTrie retrieves the positions of hyphenation patterns.
+	patternsTrie interface {
+		Get(pattern []rune) []byte
+	}
+
+	// runePatterns stores the patterns parsed from a TeX file in a trie.RuneTrie.
+	runePatterns struct {
+		trie *trie.RuneTrie
+	}
+)
+
+const (
+	// compiledMagic identifies compiled dictionaries
+	compiledMagic = "HYC1"
+
+	// compiledExtension is the extension of compiled pattern files
+	compiledExtension = ".bin"
+
+	// ErrInvalidCompiledPatterns indicates that some data is not a compiled dictionary.
+	ErrInvalidCompiledPatterns err = "invalid compiled hyphenation patterns"
+)
+
+func (p runePatterns) Get(pattern []rune) []byte {
+	val := p.trie.Get(pattern)
+	if val == nil {
+		return nil
+	}
+
+	return val.([]byte)
+}
+
+// MarshalBinary compiles a Dictionary into a compact binary form, which may be loaded with
+// LoadCompiledPatterns much faster than parsing a TeX pattern file.
+//
+// The supported pattern files are embedded in their compiled form, generated with "go generate".
+func (dict *Dictionary) MarshalBinary() ([]byte, error) {
+	var patterns []byte
+	switch p := dict.patterns.(type) {
+	case runePatterns:
+		packed, err := trie.Pack(p.trie)
+		if err != nil {
+			return nil, err
+		}
+		patterns = packed
+	case *compiledPatterns:
+		patterns = p.data
+	}
+
+	var exceptions []string
+	if source, ok := dict.exceptions.(*trie.RuneTrie); ok {
+		source.Walk(func(word []rune, value interface{}) {
+			exceptions = append(exceptions, hyphenatedException(word, value.([]int)))
+		})
+	}
+
+	data := make([]byte, 0, len(patterns)+len(dict.Identifier)+64)
+	data = append(data, compiledMagic...)
+	data = appendString(data, dict.Identifier)
+	data = append(data, byte(dict.LeftHyphenMin), byte(dict.RightHyphenMin))
+	data = binary.AppendUvarint(data, uint64(len(exceptions)))
+	for _, exception := range exceptions {
+		data = appendString(data, exception)
+	}
+
+	return append(data, patterns...), nil
+}
+
+// compiledPatterns is a trie.PackedTrie, with the data it has been loaded from.
+type compiledPatterns struct {
+	*trie.PackedTrie
+	data []byte
+}
+
+// LoadCompiledPatterns loads a Dictionary compiled with MarshalBinary.
+//
+// Patterns are not copied: the data must not be altered afterwards.
+func LoadCompiledPatterns(data []byte) (*Dictionary, error) {
+	if !isCompiled(data) {
+		return nil, ErrInvalidCompiledPatterns
+	}
+	data = data[len(compiledMagic):]
+
+	identifier, data, ok := readString(data)
+	if !ok || len(data) < 2 {
+		return nil, ErrInvalidCompiledPatterns
+	}
+
+	dict := &Dictionary{
+		exceptions:     trie.NewRuneTrie(),
+		Identifier:     identifier,
+		LeftHyphenMin:  int(data[0]),
+		RightHyphenMin: int(data[1]),
+	}
+	data = data[2:]
+
+	count, size := binary.Uvarint(data)
+	if size <= 0 {
+		return nil, ErrInvalidCompiledPatterns
+	}
+	data = data[size:]
+
+	for i := uint64(0); i < count; i++ {
+		var exception string
+		exception, data, ok = readString(data)
+		if !ok {
+			return nil, ErrInvalidCompiledPatterns
+		}
+
+		word, positions, err := readException(exception)
+		if err != nil {
+			return nil, err
+		}
+
+		dict.exceptions.Put(word, positions)
+	}
+
+	patterns, err := trie.NewPackedTrie(data)
+	if err != nil {
+		return nil, err
+	}
+
+	dict.patterns = &compiledPatterns{PackedTrie: patterns, data: data}
+
+	return dict, nil
+}
+
+// CompiledName yields the name of the compiled form of a TeX pattern file, e.g. "hyph-fr.bin" for "hyph-fr.tex".
+func CompiledName(patternfile string) string {
+	return strings.TrimSuffix(patternfile, ".tex") + compiledExtension
+}
+
+// patternName yields the name of the TeX pattern file for a compiled pattern file, e.g. "hyph-fr.tex" for "hyph-fr.bin".
+func patternName(compiledfile string) string {
+	return strings.TrimSuffix(compiledfile, compiledExtension) + ".tex"
+}
+
+func isCompiled(data []byte) bool {
+	return len(data) >= len(compiledMagic) && string(data[:len(compiledMagic)]) == compiledMagic
+}
+
+// hyphenatedException yields the hyphenated form of an exception, e.g. "ta-ble".
+func hyphenatedException(word []rune, positions []int) string {
+	var w strings.Builder
+	for i, r := range word {
+		if i < len(positions) && positions[i]%2 == 1 {
+			w.WriteRune('-')
+		}
+		w.WriteRune(r)
+	}
+
+	return w.String()
+}
+
+func appendString(data []byte, value string) []byte {
+	data = binary.AppendUvarint(data, uint64(len(value)))
+
+	return append(data, value...)
+}
+
+func readString(data []byte) (string, []byte, bool) {
+	length, size := binary.Uvarint(data)
+	if size <= 0 || uint64(len(data)-size) < length {
+		return "", nil, false
+	}
+	end := size + int(length)
+
+	return string(data[size:end]), data[end:], true
+}
@@ -0,0 +1,119 @@
+package hyphenator
+
+import (
+	"bytes"
+	"io/fs"
+	"os"
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompiledPatterns(t *testing.T) {
+	t.Parallel()
+
+	const patterns = `\message{custom patterns}
+\lefthyphenmin=1 \righthyphenmin=2
+\patterns{
+1ba
+}
+\hyphenation{
+ab-ab-ab
+foo-bar}
+`
+
+	parsed, err := LoadPatternsFrom(strings.NewReader(patterns))
+	require.NoError(t, err)
+
+	data, err := parsed.MarshalBinary()
+	require.NoError(t, err)
+
+	t.Run("should load compiled patterns", func(t *testing.T) {
+		for _, load := range []func() (*Dictionary, error){
+			func() (*Dictionary, error) { return LoadCompiledPatterns(data) },
+			func() (*Dictionary, error) { return LoadPatternsFrom(bytes.NewReader(data)) },
+		} {
+			dict, err := load()
+			require.NoError(t, err)
+			require.Equal(t, parsed.String(), dict.String())
+			require.Equal(t, 1, dict.LeftHyphenMin)
+			require.Equal(t, 2, dict.RightHyphenMin)
+
+			h := New(WithDictionary(dict))
+			require.Equal(t, toRunes([]string{"ca", "bab"}), h.BreakWordString("cabab"))
+			require.Equal(t, toRunes([]string{"foo", "bar"}), h.BreakWordString("foobar"))
+			require.Equal(t, toRunes([]string{"ab", "ab", "ab"}), h.BreakWordString("ababab"))
+
+			recompiled, err := dict.MarshalBinary()
+			require.NoError(t, err)
+			require.Equal(t, data, recompiled)
+		}
+	})
+
+	t.Run("should embed the compiled form of every pattern file", func(t *testing.T) {
+		sources, err := fs.Glob(os.DirFS(folder), "*.tex")
+		require.NoError(t, err)
+
+		supported, err := SupportedPatterns()
+		require.NoError(t, err)
+		require.Equal(t, sources, supported)
+
+		words := strings.Fields("hyphenation typesetting supercalifragilisticexpialidocious anticonstitutionnellement Silbentrennung")
+
+		for _, patterns := range supported {
+			parsed, err := LoadPatternsFS(os.DirFS(folder), patterns)
+			require.NoError(t, err)
+
+			expected, err := parsed.MarshalBinary()
+			require.NoError(t, err)
+
+			data, err := texFS.ReadFile(path.Join(folder, CompiledName(patterns)))
+			require.NoError(t, err)
+			require.Truef(t, bytes.Equal(expected, data),
+				"%s is stale: run go generate to compile %s again", CompiledName(patterns), patterns,
+			)
+
+			compiled, err := LoadPatterns(patterns)
+			require.NoError(t, err)
+			require.Equal(t, parsed.String(), compiled.String())
+			require.Equal(t, parsed.LeftHyphenMin, compiled.LeftHyphenMin)
+			require.Equal(t, parsed.RightHyphenMin, compiled.RightHyphenMin)
+
+			hp, hc := New(WithDictionary(parsed)), New(WithDictionary(compiled))
+			for _, word := range words {
+				require.Equalf(t, hp.BreakWordString(word), hc.BreakWordString(word), "%s: %s", patterns, word)
+			}
+		}
+	})
+
+	t.Run("should reject invalid compiled patterns", func(t *testing.T) {
+		_, err := LoadCompiledPatterns([]byte("HYC0"))
+		require.ErrorIs(t, err, ErrInvalidCompiledPatterns)
+
+		_, err = LoadCompiledPatterns(data[:len(data)-1])
+		require.Error(t, err)
+	})
+
+	t.Run("should not panic on corrupted compiled patterns", func(t *testing.T) {
+		for i := range data {
+			for _, mask := range []byte{0x01, 0x80, 0xff} {
+				corrupted := append([]byte{}, data...)
+				corrupted[i] ^= mask
+
+				dict, err := LoadCompiledPatterns(corrupted)
+				if err != nil {
+					continue
+				}
+
+				h := New(WithDictionary(dict))
+				require.NotPanicsf(t, func() {
+					for _, word := range []string{"cabab", "ababab", "foobar", "hyphenation"} {
+						_ = h.BreakWordString(word)
+					}
+				}, "byte %d corrupted with mask %#x", i, mask)
+			}
+		}
+	})
+}
@@ -3,8 +3,12 @@ package hyphenator
 import (
 	"bufio"
 	"fmt"
+	"io"
+	"io/fs"
 	"path"
+	"regexp"
 	"sort"
+	"strconv"
 	"strings"
 	"unicode"
 
@@ -12,9 +16,22 @@ import (
 )
 
 const (
-	folder = "languages"
+	folder            = "languages"
+	defaultIdentifier = "patterns"
+
+	// ErrMalformedPattern indicates that a pattern is not made of letters, with single digits between them.
+	ErrMalformedPattern err = "malformed hyphenation pattern"
+
+	// ErrMalformedException indicates that an exception is not made of letters separated by single hyphens.
+	ErrMalformedException err = "malformed hyphenation exception"
 )
 
+type err string
+
+func (e err) Error() string {
+	return string(e)
+}
+
 // Dictionary represents a dictionary for hyphenation rules: patterns and exceptions.
 //
 // Dictionary knows how to load a TeX hyphenation patterns file from supported languages.
@@ -27,12 +44,40 @@ const (
 // * German: de patterns (1996)
 // * French:  fr-FR patterns
 // * Spanish: es patterns
+//
+// Other pattern files may be loaded with LoadPatternsFS or LoadPatternsFrom, then either passed to a Hyphenator
+// with WithDictionary, or registered for a language with RegisterDictionary.
 type Dictionary struct {
-	exceptions trie.Trier // e.g., "computer" => [3,5] = "com-pu-ter"
-	patterns   trie.Trier // where we store patterns and positions
-	Identifier string     // Identifies the dictionary
+	exceptions trie.Trier   // e.g., "computer" => [3,5] = "com-pu-ter"
+	patterns   patternsTrie // where we store patterns and positions
+	Identifier string       // Identifies the dictionary
+
+	// LeftHyphenMin is the minimum number of runes before the first hyphenation point of a word,
+	// as specified by the pattern file (0 when unspecified).
+	LeftHyphenMin int
+
+	// RightHyphenMin is the minimum number of runes after the last hyphenation point of a word,
+	// as specified by the pattern file (0 when unspecified).
+	RightHyphenMin int
+}
+
+// headerState keeps track of the hyphenmins found in the header of a hyph-utf8 pattern file:
+//
+//	% hyphenmins:
+//	%     typesetting:
+//	%         left: 2
+//	%         right: 3
+type headerState struct {
+	inHyphenmins bool
+	indent       int
+	section      string
 }
 
+var (
+	rexHyphenMin = regexp.MustCompile(`\\(left|right)hyphenmin\s*=?\s*(\d+)`)
+	rexHeaderKey = regexp.MustCompile(`^%(\s*)([a-z]+):\s*(\d*)`)
+)
+
 func isTeXComment(line string) bool {
 	return len(line) == 0 ||
 		strings.HasPrefix(line, "%") ||
@@ -41,6 +86,8 @@ func isTeXComment(line string) bool {
 }
 
 // SupportedPatterns lists all currently supported language files with hyphenation patterns.
+//
+// Pattern files are embedded in their compiled form only, and are listed with their TeX name, e.g. "hyph-fr.tex".
 func SupportedPatterns() ([]string, error) {
 	entries, err := texFS.ReadDir(folder)
 	if err != nil {
@@ -49,11 +96,11 @@ func SupportedPatterns() ([]string, error) {
 
 	var result []string
 	for _, entry := range entries {
-		if entry.IsDir() {
+		if entry.IsDir() || path.Ext(entry.Name()) != compiledExtension {
 			continue
 		}
 
-		result = append(result, entry.Name())
+		result = append(result, patternName(entry.Name()))
 	}
 
 	sort.Strings(result)
@@ -61,8 +108,10 @@ func SupportedPatterns() ([]string, error) {
 	return result, nil
 }
 
-// LoadPatterns loads a pattern file as a Dictionary
-// from the embedded file system.
+// LoadPatterns loads a supported pattern file as a Dictionary
+// from the embedded file system, where the pattern file is found in its compiled form.
+//
+// The original pattern files, in the "languages" folder, are in the TeX format.
 //
 // Patterns are enclosed like so:
 //
@@ -84,12 +133,15 @@ func SupportedPatterns() ([]string, error) {
 //
 //	"a5ban" => (a)(5b)(a)(n) => positions["aban"] = [0,5,0,0].
 func LoadPatterns(patternfile string) (*Dictionary, error) {
-	const (
-		messageSection    = `\message{`
-		exceptionsSection = `\hyphenation{`
-	)
+	return LoadPatternsFS(texFS, path.Join(folder, CompiledName(patternfile))) // known pattern files are embedded compiled
+}
 
-	file, err := texFS.Open(path.Join(folder, patternfile)) // known pattern files are embedded
+// LoadPatternsFS loads a pattern file as a Dictionary from any file system,
+// e.g. os.DirFS or an embed.FS.
+//
+// The file follows the same TeX format as the pattern files used by LoadPatterns.
+func LoadPatternsFS(fsys fs.FS, name string) (*Dictionary, error) {
+	file, err := fsys.Open(name)
 	if err != nil {
 		return nil, err
 	}
@@ -97,41 +149,134 @@ func LoadPatterns(patternfile string) (*Dictionary, error) {
 		_ = file.Close()
 	}()
 
+	dict, err := LoadPatternsFrom(file)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+
+	if dict.Identifier == defaultIdentifier {
+		dict.Identifier = fmt.Sprintf("%s: %s", defaultIdentifier, path.Base(name))
+	}
+
+	return dict, nil
+}
+
+// LoadPatternsFrom loads hyphenation patterns as a Dictionary from a reader,
+// in the TeX format of the pattern files used by LoadPatterns.
+//
+// Patterns are expected in a "\patterns{" section, and exceptions in a "\hyphenation{" section.
+// The hyphenmins are read from "\lefthyphenmin" and "\righthyphenmin" commands, or from the
+// header of hyph-utf8 pattern files. Other TeX commands and comments are ignored.
+//
+// Malformed patterns or exceptions are reported as an error with the line number.
+//
+// Patterns compiled with Dictionary.MarshalBinary are recognized and loaded as well.
+func LoadPatternsFrom(r io.Reader) (*Dictionary, error) {
+	const (
+		messageSection    = `\message{`
+		exceptionsSection = `\hyphenation{`
+	)
+
+	buffered := bufio.NewReader(r)
+	if magic, _ := buffered.Peek(len(compiledMagic)); isCompiled(magic) {
+		// patterns compiled with MarshalBinary
+		data, err := io.ReadAll(buffered)
+		if err != nil {
+			return nil, err
+		}
+
+		return LoadCompiledPatterns(data)
+	}
+
+	patterns := trie.NewRuneTrie()
 	dict := &Dictionary{
 		exceptions: trie.NewRuneTrie(),
-		patterns:   trie.NewRuneTrie(),
-		Identifier: fmt.Sprintf("patterns: %s", patternfile), // default identifier
+		patterns:   runePatterns{trie: patterns},
+		Identifier: defaultIdentifier,
 	}
 
-	scanner := bufio.NewScanner(file)
+	var (
+		lineNumber   int
+		inExceptions bool
+		header       headerState
+	)
+
+	scanner := bufio.NewScanner(buffered)
 	for scanner.Scan() {
-		line := scanner.Text()
+		lineNumber++
+		line := strings.TrimSpace(scanner.Text())
 
 		switch {
 		case strings.HasPrefix(line, messageSection):
 			// extract the patterns identifier
 			dict.Identifier = strings.TrimPrefix(line, messageSection)
 
+			continue
+
 		case strings.HasPrefix(line, exceptionsSection):
 			// decode the exceptions section
-			for scanner.Scan() {
-				line = scanner.Text()
-				dict.exceptions.Put(dict.readException(line))
-			}
+			inExceptions = true
+			line = strings.TrimPrefix(line, exceptionsSection)
+
+		case strings.HasPrefix(line, "%"):
+			// comments may specify hyphenmins in the header of the file
+			dict.readHeader(line, &header)
+
+			continue
+
+		case rexHyphenMin.MatchString(line):
+			// \lefthyphenmin=2 \righthyphenmin=3
+			dict.readHyphenMins(line)
+
+			continue
 
 		case isTeXComment(line):
 			// ignore comments, TeX commands, etc.
+			if strings.HasPrefix(line, "}") {
+				inExceptions = false
+			}
+
 			continue
+		}
+
+		if comment := strings.IndexRune(line, '%'); comment >= 0 {
+			line = line[:comment]
+		}
+
+		// a closing brace may end the line
+		isClosed := strings.HasSuffix(line, "}")
+		line = strings.TrimSuffix(line, "}")
+
+		// decode a patterns section: ".ab1a" "abe4l3in", ... or an exceptions section: "ta-ble"
+		for _, field := range strings.Fields(line) {
+			if inExceptions {
+				word, positions, err := readException(field)
+				if err != nil {
+					return nil, fmt.Errorf("line %d: %q: %w", lineNumber, field, err)
+				}
 
-		default:
-			// decode a patterns section: ".ab1a" "abe4l3in", ...
-			patterns := strings.Fields(line)
-			for _, pattern := range patterns {
-				dict.patterns.Put(dict.readPattern(pattern))
+				dict.exceptions.Put(word, positions)
+
+				continue
+			}
+
+			pattern, positions, err := dict.readPattern(field)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %q: %w", lineNumber, field, err)
 			}
+
+			patterns.Put(pattern, positions)
+		}
+
+		if isClosed {
+			inExceptions = false
 		}
 	}
 
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("line %d: %w", lineNumber, err)
+	}
+
 	return dict, nil
 }
 
@@ -163,18 +308,26 @@ func atoiRune(r rune) int {
 }
 
 // readPattern reads a pattern in the patterns section.
-func (dict *Dictionary) readPattern(line string) ([]rune, []int) {
+func (dict *Dictionary) readPattern(line string) ([]rune, []byte, error) {
 	wasdigit := false                     // has the last char been a digit?
 	pattern := make([]rune, 0, len(line)) // will become the pattern without positions
-	positions := make([]int, 0, 10)       // we'll extract positions
+	positions := make([]byte, 0, 10)      // we'll extract positions
 
 	for _, char := range line { // iterate over the runes for this pattern
-		if unicode.IsDigit(char) {
+		switch {
+		case unicode.IsDigit(char):
+			if wasdigit {
+				return nil, nil, ErrMalformedPattern // a single digit is expected between two letters
+			}
+
 			d := atoiRune(char)
-			positions = append(positions, d) // add to positions array
+			positions = append(positions, byte(d)) // add to positions array
 			wasdigit = true
 
 			continue
+
+		case char != '.' && !isPatternLetter(char):
+			return nil, nil, ErrMalformedPattern
 		}
 
 		// '.' or alphabetic rune
@@ -186,38 +339,110 @@ func (dict *Dictionary) readPattern(line string) ([]rune, []int) {
 		}
 	}
 
-	return pattern, positions
+	for i, char := range pattern {
+		if char == '.' && i > 0 && i < len(pattern)-1 {
+			return nil, nil, ErrMalformedPattern // dots only mark the start or the end of a word
+		}
+	}
+
+	if len(pattern) == 0 || (len(pattern) == 1 && pattern[0] == '.') {
+		return nil, nil, ErrMalformedPattern
+	}
+
+	return pattern, positions, nil
 }
 
-// readExceptions processes a line from the exceptions section in a pattern file
+// readExceptions processes a word from the exceptions section in a pattern file
 // ("\hyphenation{").
 //
 // Exceptions are encoded as predefined hyphenation points for known words:
 //
 //	ex-cep-tion
 //	ta-ble
-func (dict *Dictionary) readException(line string) ([]rune, []int) {
-	if isTeXComment(line) {
-		return nil, nil
-	}
-
+func readException(line string) ([]rune, []int, error) {
 	positions := make([]int, 0, 5)
+	word := make([]rune, 0, len(line))
 
 	var washyphen bool
 	for _, char := range line {
 		switch {
 		case hyphens.Contains(char):
+			if washyphen || len(word) == 0 {
+				return nil, nil, ErrMalformedException // no consecutive or leading hyphens
+			}
+
 			positions = append(positions, 1) // possible break point
 			washyphen = true
+		case !isPatternLetter(char):
+			return nil, nil, ErrMalformedException
 		case washyphen: // skip letter
 			washyphen = false
+			word = append(word, unicode.ToLower(char))
 		default: // a letter without a '-'
 			positions = append(positions, 0)
+			word = append(word, unicode.ToLower(char))
 		}
 	}
-	word := strings.ToLower(strings.ReplaceAll(line, "-", ""))
 
-	return []rune(word), positions
+	if washyphen || len(word) == 0 {
+		return nil, nil, ErrMalformedException // no trailing hyphen
+	}
+
+	return word, positions, nil
+}
+
+// isPatternLetter tells if a rune may be found in the letters of a pattern or of an exception.
+func isPatternLetter(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsMark(r) || r == '\'' || r == '’'
+}
+
+// readHeader reads the hyphenmins to be used for typesetting from a comment line in the header of a pattern file.
+func (dict *Dictionary) readHeader(line string, header *headerState) {
+	matches := rexHeaderKey.FindStringSubmatch(line)
+	if matches == nil {
+		return
+	}
+
+	indent, key, value := len(matches[1]), matches[2], matches[3]
+	if key == "hyphenmins" {
+		header.inHyphenmins, header.indent = true, indent
+
+		return
+	}
+
+	if !header.inHyphenmins {
+		return
+	}
+
+	if indent <= header.indent {
+		header.inHyphenmins = false
+
+		return
+	}
+
+	switch {
+	case key == "typesetting" || key == "generation":
+		header.section = key
+	case header.section != "typesetting" || value == "":
+		return
+	case key == "left":
+		dict.LeftHyphenMin, _ = strconv.Atoi(value)
+	case key == "right":
+		dict.RightHyphenMin, _ = strconv.Atoi(value)
+	}
+}
+
+// readHyphenMins reads the hyphenmins set by TeX commands.
+func (dict *Dictionary) readHyphenMins(line string) {
+	for _, matches := range rexHyphenMin.FindAllStringSubmatch(line, -1) {
+		value, _ := strconv.Atoi(matches[2])
+
+		if matches[1] == "left" {
+			dict.LeftHyphenMin = value
+		} else {
+			dict.RightHyphenMin = value
+		}
+	}
 }
 
 // String returns the identifier of the pattern file (by default, this is the file name).
@@ -1,9 +1,13 @@
 package hyphenator
 
 import (
+	"io/fs"
+	"strings"
 	"testing"
+	"testing/fstest"
 
 	"github.com/stretchr/testify/require"
+	"golang.org/x/text/language"
 )
 
 func TestSupportedPatterns(t *testing.T) {
@@ -34,3 +38,112 @@ func TestLoadPatterns(t *testing.T) {
 		require.NotNil(t, dict)
 	}
 }
+
+func TestLoadPatternsFrom(t *testing.T) {
+	t.Parallel()
+
+	const patterns = `% custom patterns
+\message{custom patterns}
+\patterns{
+1ba % a comment
+}
+\hyphenation{
+ab-ab-ab
+foo-bar}
+`
+
+	t.Run("should load patterns and exceptions from a reader", func(t *testing.T) {
+		dict, err := LoadPatternsFrom(strings.NewReader(patterns))
+		require.NoError(t, err)
+		require.Equal(t, "custom patterns}", dict.String())
+
+		h := New(WithDictionary(dict))
+		require.Equal(t, toRunes([]string{"ca", "bab"}), h.BreakWordString("cabab"))
+		require.Equal(t, toRunes([]string{"foo", "bar"}), h.BreakWordString("foobar"))
+		require.Equal(t, toRunes([]string{"ab", "ab", "ab"}), h.BreakWordString("ababab"))
+	})
+
+	t.Run("should load patterns from a file system", func(t *testing.T) {
+		fsys := fstest.MapFS{
+			"patterns/hyph-custom.tex": &fstest.MapFile{Data: []byte("\\patterns{\n1ba\n}\n")},
+		}
+
+		dict, err := LoadPatternsFS(fsys, "patterns/hyph-custom.tex")
+		require.NoError(t, err)
+		require.Equal(t, "patterns: hyph-custom.tex", dict.String())
+
+		_, err = LoadPatternsFS(fsys, "patterns/missing.tex")
+		require.ErrorIs(t, err, fs.ErrNotExist)
+	})
+
+	t.Run("should report malformed patterns with their line number", func(t *testing.T) {
+		for _, malformed := range []struct {
+			Text     string
+			Expected error
+			Line     string
+		}{
+			{Text: "\\patterns{\na1b\na12b\n}", Expected: ErrMalformedPattern, Line: "line 3"},
+			{Text: "\\patterns{\nab.c\n}", Expected: ErrMalformedPattern, Line: "line 2"},
+			{Text: "\\patterns{\n1a2 b/c\n}", Expected: ErrMalformedPattern, Line: "line 2"},
+			{Text: "\\patterns{\n.\n}", Expected: ErrMalformedPattern, Line: "line 2"},
+			{Text: "\\patterns{\n1ba\n}\n\\hyphenation{\nta--ble\n}", Expected: ErrMalformedException, Line: "line 5"},
+			{Text: "\\hyphenation{\nta-ble-\n}", Expected: ErrMalformedException, Line: "line 2"},
+			{Text: "\\hyphenation{\nta-ble\nta3ble}", Expected: ErrMalformedException, Line: "line 3"},
+		} {
+			_, err := LoadPatternsFrom(strings.NewReader(malformed.Text))
+			require.ErrorIs(t, err, malformed.Expected)
+			require.Contains(t, err.Error(), malformed.Line)
+		}
+	})
+}
+
+func TestRegisterDictionary(t *testing.T) {
+	t.Parallel()
+
+	dict, err := LoadPatternsFrom(strings.NewReader("\\patterns{\n1ba\n}\n"))
+	require.NoError(t, err)
+
+	RegisterDictionary(language.Italian, dict)
+
+	t.Run("should pick a registered language", func(t *testing.T) {
+		h := New(WithLanguage("it"))
+		require.Equal(t, dict, h.Dictionary)
+		require.Equal(t, toRunes([]string{"aba", "bab"}), h.BreakWordString("ababab"))
+
+		h = New(WithLanguageTag(language.MustParse("it-CH")))
+		require.Equal(t, dict, h.Dictionary)
+	})
+
+	t.Run("should not alter other languages", func(t *testing.T) {
+		h := New(WithLanguage("fr"))
+		require.Equal(t, "patterns: hyph-fr.tex", h.String())
+
+		h = New()
+		require.Equal(t, "patterns: ushyphmax.tex", h.String())
+	})
+}
+
+func TestRegisterPatterns(t *testing.T) {
+	t.Parallel()
+
+	fsys := fstest.MapFS{
+		"hyph-nl.tex":  &fstest.MapFile{Data: []byte("\\patterns{\n1ba\n}\n")},
+		"hyph-bad.tex": &fstest.MapFile{Data: []byte("\\patterns{\na12b\n}\n")},
+	}
+
+	t.Run("should load registered patterns on first use", func(t *testing.T) {
+		RegisterPatterns(language.Dutch, fsys, "hyph-nl.tex")
+
+		h := New(WithLanguage("nl-BE"))
+		require.Equal(t, "patterns: hyph-nl.tex", h.String())
+		require.Equal(t, toRunes([]string{"ca", "bab"}), h.BreakWordString("cabab"))
+		require.Same(t, h.Dictionary, New(WithLanguageTag(language.Dutch)).Dictionary)
+	})
+
+	t.Run("should fall back to the default patterns", func(t *testing.T) {
+		RegisterPatterns(language.Polish, fsys, "hyph-bad.tex")
+
+		h := New(WithLanguageTag(language.Polish))
+		require.Equal(t, "patterns: ushyphmax.tex", h.String())
+	})
+}
@@ -1,10 +1,15 @@
+//go:build !bootstrap
+
 package hyphenator
 
 import (
 	"embed"
 )
 
-// Embeds in the build all *.tex pattern files in the "languages" folder.
+// Embeds in the build the compiled form (*.bin) of all pattern files in the "languages" folder.
+//
+// Compiled patterns are generated from the *.tex pattern files by "go generate",
+// which builds this package with the "bootstrap" tag, so no compiled pattern is required to run the generators.
 
-//go:embed languages/*.tex
+//go:embed languages/*.bin
 var texFS embed.FS
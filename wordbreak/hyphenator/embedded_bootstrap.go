@@ -0,0 +1,11 @@
+//go:build bootstrap
+
+package hyphenator
+
+import (
+	"embed"
+)
+
+// With the "bootstrap" build tag, no pattern is embedded: this is how the generators in the "languages" folder
+// build this package before any pattern is compiled.
+var texFS embed.FS
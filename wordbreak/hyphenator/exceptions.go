@@ -0,0 +1,159 @@
+package hyphenator
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// AddException adds hyphenation exceptions to this hyphenator, e.g. "Ku-ber-ne-tes".
+//
+// Hyphens mark the only legit hyphenation points of a word: a word without hyphens is never hyphenated.
+// Exceptions are matched regardless of case, and take precedence over the exceptions and patterns of the
+// dictionary. Other hyphenators using the same dictionary are not affected.
+//
+// The minimum lengths before and after an hyphenation point still apply.
+func (h *Hyphenator) AddException(words ...string) error {
+	exceptions := make(map[string][]int, len(words))
+	for _, word := range words {
+		key, positions, err := readException(word)
+		if err != nil {
+			return fmt.Errorf("%q: %w", word, err)
+		}
+
+		exceptions[string(key)] = positions
+	}
+
+	h.mx.Lock()
+	defer h.mx.Unlock()
+
+	if h.userExceptions == nil {
+		h.userExceptions = make(map[string][]int, len(exceptions))
+	}
+
+	for key, positions := range exceptions {
+		h.userExceptions[key] = positions
+	}
+
+	return nil
+}
+
+// RemoveException removes hyphenation exceptions from this hyphenator.
+//
+// Words may be specified with or without hyphens. The exceptions from the dictionary for these words are
+// ignored as well, so these words are hyphenated according to the patterns of the dictionary.
+func (h *Hyphenator) RemoveException(words ...string) {
+	h.mx.Lock()
+	defer h.mx.Unlock()
+
+	if h.userExceptions == nil {
+		h.userExceptions = make(map[string][]int, len(words))
+	}
+
+	for _, word := range words {
+		key := strings.Map(func(r rune) rune {
+			if hyphens.Contains(r) {
+				return -1
+			}
+
+			return unicode.ToLower(r)
+		}, word)
+
+		h.userExceptions[key] = nil
+	}
+}
+
+// Exceptions lists the hyphenation exceptions added to this hyphenator, in lower case
+// and with their hyphenation points, e.g. "ku-ber-ne-tes".
+//
+// Exceptions from the dictionary are not listed.
+func (h *Hyphenator) Exceptions() []string {
+	h.mx.RLock()
+	defer h.mx.RUnlock()
+
+	result := make([]string, 0, len(h.userExceptions))
+	for key, positions := range h.userExceptions {
+		if positions == nil {
+			continue
+		}
+
+		result = append(result, hyphenatedException([]rune(key), positions))
+	}
+
+	sort.Strings(result)
+
+	return result
+}
+
+// LoadExceptions adds hyphenation exceptions to this hyphenator from a plain text file,
+// with words separated by blank space or new lines, e.g.:
+//
+//	% product names
+//	Ku-ber-ne-tes
+//	Java-Script Type-Script
+//
+// Lines starting with "%" or "#" are comments.
+// Malformed exceptions are reported as an error with the line number.
+func (h *Hyphenator) LoadExceptions(r io.Reader) error {
+	var (
+		lineNumber int
+		words      []string
+	)
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, "%") || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		for _, word := range strings.Fields(line) {
+			if _, _, err := readException(word); err != nil {
+				return fmt.Errorf("line %d: %q: %w", lineNumber, word, err)
+			}
+
+			words = append(words, word)
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("line %d: %w", lineNumber, err)
+	}
+
+	return h.AddException(words...)
+}
+
+// localException looks up the exceptions of this hyphenator, for a word in lower case.
+func (h *Hyphenator) localException(word []rune) ([]int, bool) {
+	h.mx.RLock()
+	defer h.mx.RUnlock()
+
+	if len(h.userExceptions) == 0 {
+		return nil, false
+	}
+
+	positions, ok := h.userExceptions[string(word)]
+
+	return positions, ok
+}
+
+// toLower yields a word in lower case. The word is copied only when needed.
+func toLower(word []rune) []rune {
+	for i, r := range word {
+		if lower := unicode.ToLower(r); lower != r {
+			result := make([]rune, len(word))
+			copy(result, word[:i])
+			for j := i; j < len(word); j++ {
+				result[j] = unicode.ToLower(word[j])
+			}
+
+			return result
+		}
+	}
+
+	return word
+}
@@ -0,0 +1,68 @@
+package hyphenator
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestExceptions(t *testing.T) {
+	t.Parallel()
+
+	t.Run("should add exceptions to a hyphenator", func(t *testing.T) {
+		h := New()
+		require.Equal(t, toRunes([]string{"Ku", "ber", "netes"}), h.BreakWordString("Kubernetes"))
+
+		require.NoError(t, h.AddException("Ku-ber-ne-tes", "typeset"))
+		require.Equal(t, toRunes([]string{"Ku", "ber", "ne", "tes"}), h.BreakWordString("Kubernetes"))
+		require.Equal(t, toRunes([]string{"KU", "BER", "NE", "TES"}), h.BreakWordString("KUBERNETES"))
+		require.Equal(t, toRunes([]string{"typeset"}), h.BreakWordString("typeset"))
+		require.Equal(t, []string{"ku-ber-ne-tes", "typeset"}, h.Exceptions())
+
+		t.Run("without altering other hyphenators", func(t *testing.T) {
+			other := New()
+			require.Equal(t, toRunes([]string{"Ku", "ber", "netes"}), other.BreakWordString("Kubernetes"))
+			require.Empty(t, other.Exceptions())
+		})
+	})
+
+	t.Run("should remove exceptions", func(t *testing.T) {
+		h := New()
+		require.Equal(t, toRunes([]string{"ta", "ble"}), h.BreakWordString("table"))
+		require.Equal(t, toRunes([]string{"project"}), h.BreakWordString("project"))
+
+		require.NoError(t, h.AddException("Ku-ber-ne-tes"))
+		h.RemoveException("Kubernetes", "pro-ject")
+
+		require.Equal(t, toRunes([]string{"Ku", "ber", "netes"}), h.BreakWordString("Kubernetes"))
+		require.Equal(t, toRunes([]string{"pro", "ject"}), h.BreakWordString("project"))
+		require.Empty(t, h.Exceptions())
+
+		require.Equal(t, toRunes([]string{"project"}), New().BreakWordString("project"))
+	})
+
+	t.Run("should load exceptions from a plain text file", func(t *testing.T) {
+		const exceptions = `% product names
+Ku-ber-ne-tes
+
+# languages
+Java-Script Type-Script
+`
+		h := New()
+		require.NoError(t, h.LoadExceptions(strings.NewReader(exceptions)))
+		require.Equal(t, []string{"java-script", "ku-ber-ne-tes", "type-script"}, h.Exceptions())
+		require.Equal(t, toRunes([]string{"Java", "Script"}), h.BreakWordString("JavaScript"))
+	})
+
+	t.Run("should report malformed exceptions", func(t *testing.T) {
+		h := New()
+
+		require.ErrorIs(t, h.AddException("ku--ber"), ErrMalformedException)
+
+		err := h.LoadExceptions(strings.NewReader("Ku-ber-ne-tes\nJava-Script Type3Script\n"))
+		require.ErrorIs(t, err, ErrMalformedException)
+		require.Contains(t, err.Error(), "line 2")
+		require.Empty(t, h.Exceptions())
+	})
+}
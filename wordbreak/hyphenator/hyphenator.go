@@ -1,6 +1,7 @@
 package hyphenator
 
 import (
+	"sync"
 	"unicode"
 
 	iface "github.com/fredbi/go-typeset/wordbreak"
@@ -18,6 +19,9 @@ var (
 type Hyphenator struct {
 	*Dictionary
 	*options
+
+	mx             sync.RWMutex
+	userExceptions map[string][]int // runtime exceptions for this hyphenator. nil positions stand for removed exceptions
 }
 
 // New hyphenator.
@@ -26,7 +30,24 @@ func New(opts ...Option) *Hyphenator {
 		options: defaultOptions(opts),
 	}
 
-	h.Dictionary = loadDictFromCache(langToPattern(h.lang))
+	h.Dictionary = h.dict
+	if h.Dictionary == nil {
+		h.Dictionary = langToDictionary(h.lang)
+	}
+
+	if h.Dictionary == nil {
+		// the patterns for this language could not be loaded
+		h.Dictionary = defaultDictionary()
+	}
+
+	// hyphenmins default to the ones specified by the patterns
+	if !h.hasMinLeft && h.LeftHyphenMin > 0 {
+		h.minLeft = h.LeftHyphenMin
+	}
+
+	if !h.hasMinRight && h.RightHyphenMin > 0 {
+		h.minRight = h.RightHyphenMin
+	}
 
 	return h
 }
@@ -58,7 +79,7 @@ func (h *Hyphenator) BreakWord(word []rune) [][]rune {
 
 	// allocate buffers once for all iterations
 	hasPatterns := false
-	allBreakPoints := make([][]int, 0, wordLength+2)
+	allBreakPoints := make([][]byte, 0, wordLength+2)
 	positions := make([]int, 30) // the resulting hyphenation positions. A reasonable size is preallocated.
 	fragmentBuffer := make([]rune, 0, wordLength+2)
 
@@ -84,7 +105,14 @@ func (h *Hyphenator) BreakWord(word []rune) [][]rune {
 }
 
 func (h *Hyphenator) isException(word []rune) ([]int, bool) {
-	val := h.exceptions.Get(word)
+	lower := toLower(word)
+
+	positions, isLocal := h.localException(lower)
+	if isLocal {
+		return positions, positions != nil // an exception removed from this hyphenator falls back to patterns
+	}
+
+	val := h.exceptions.Get(lower)
 	if val != nil {
 		return val.([]int), true
 	}
@@ -92,7 +120,7 @@ func (h *Hyphenator) isException(word []rune) ([]int, bool) {
 	return nil, false
 }
 
-func (h *Hyphenator) isPattern(index int, word []rune, fragment []rune, result [][]int) ([][]int, bool) {
+func (h *Hyphenator) isPattern(index int, word []rune, fragment []rune, result [][]byte) ([][]byte, bool) {
 	// ".word." => ".w", ".wo", ".wor", ".word", ".word." ("." is skipped)
 	// "word."  => "w", "wo", "wor", "word", "word."
 	const dot = '.'
@@ -111,19 +139,17 @@ func (h *Hyphenator) isPattern(index int, word []rune, fragment []rune, result [
 	for i := start; i < len(word); i++ {
 		r := unicode.ToLower(word[i])
 		fragment = append(fragment, r)
-		val := h.patterns.Get(fragment)
-		if val == nil {
+		positions := h.patterns.Get(fragment)
+		if positions == nil {
 			continue
 		}
 
-		positions := val.([]int)
 		result = append(result, positions)
 	}
 
 	fragment = append(fragment, dot)
-	val := h.patterns.Get(fragment)
-	if val != nil {
-		positions := val.([]int)
+	positions := h.patterns.Get(fragment)
+	if positions != nil {
 		result = append(result, positions)
 	}
 
@@ -141,7 +167,7 @@ func (h *Hyphenator) isPattern(index int, word []rune, fragment []rune, result [
 //
 //	 after merge at position 1:
 //		p = [0,2,7,3].
-func mergeBreakPoints(positions []int, partialPositions [][]int, at int) []int {
+func mergeBreakPoints(positions []int, partialPositions [][]byte, at int) []int {
 	for _, partialPosition := range partialPositions {
 		for relativeAt, num := range partialPosition { // for every relative position
 			if missing := at + relativeAt - len(positions) + 1; missing > 0 {
@@ -151,8 +177,8 @@ func mergeBreakPoints(positions []int, partialPositions [][]int, at int) []int {
 				}
 			}
 
-			if num > positions[at+relativeAt] { // new pos greater than current pos?
-				positions[at+relativeAt] = num
+			if int(num) > positions[at+relativeAt] { // new pos greater than current pos?
+				positions[at+relativeAt] = int(num)
 			}
 		}
 	}
@@ -166,3 +166,118 @@ func toStrings(in [][]rune) []string {
 
 	return out
 }
+
+func TestHyphenMins(t *testing.T) {
+	t.Parallel()
+
+	t.Run("should use the hyphenmins of every language", func(t *testing.T) {
+		for _, toPin := range []struct {
+			Lang  string
+			Left  int
+			Right int
+		}{
+			{Lang: "en-US", Left: 2, Right: 3},
+			{Lang: "en-GB", Left: 2, Right: 3},
+			{Lang: "de", Left: 2, Right: 2},
+			{Lang: "es", Left: 2, Right: 2},
+			{Lang: "fr", Left: 2, Right: 2},
+		} {
+			lang := toPin
+
+			h := New(WithLanguage(lang.Lang))
+			require.Equalf(t, lang.Left, h.LeftHyphenMin, "unexpected lefthyphenmin for %s", lang.Lang)
+			require.Equalf(t, lang.Right, h.RightHyphenMin, "unexpected righthyphenmin for %s", lang.Lang)
+			require.Equal(t, lang.Left, h.minLeft)
+			require.Equal(t, lang.Right, h.minRight)
+		}
+	})
+
+	t.Run("should apply the hyphenmins of the language", func(t *testing.T) {
+		h := New()
+
+		require.Equal(t, toRunes([]string{"hy", "phen", "ated"}), h.BreakWordString("hyphenated"))
+		require.Equal(t, toRunes([]string{"started"}), h.BreakWordString("started"))
+	})
+
+	t.Run("should override the hyphenmins of the language", func(t *testing.T) {
+		h := New(WithMinRight(2))
+
+		require.Equal(t, toRunes([]string{"hy", "phen", "at", "ed"}), h.BreakWordString("hyphenated"))
+		require.Equal(t, toRunes([]string{"start", "ed"}), h.BreakWordString("started"))
+	})
+
+	t.Run("should read hyphenmins from the header of hyph-utf8 pattern files", func(t *testing.T) {
+		const patterns = `% hyphenmins:
+%     generation:
+%         left: 1
+%         right: 1
+%     typesetting:
+%         left: 3
+%         right: 4
+% texlive:
+%     synonyms:
+%         left: 5
+\patterns{
+1ba
+}
+`
+		dict, err := LoadPatternsFrom(strings.NewReader(patterns))
+		require.NoError(t, err)
+		require.Equal(t, 3, dict.LeftHyphenMin)
+		require.Equal(t, 4, dict.RightHyphenMin)
+	})
+
+	t.Run("should read hyphenmins from TeX commands", func(t *testing.T) {
+		dict, err := LoadPatternsFrom(strings.NewReader("\\lefthyphenmin=1 \\righthyphenmin 2\n\\patterns{\n1ba\n}\n"))
+		require.NoError(t, err)
+		require.Equal(t, 1, dict.LeftHyphenMin)
+		require.Equal(t, 2, dict.RightHyphenMin)
+
+		h := New(WithDictionary(dict))
+		require.Equal(t, toRunes([]string{"a", "ba", "ba"}), h.BreakWordString("ababa"))
+	})
+}
+
+func TestUnloadablePatterns(t *testing.T) {
+	// not parallel: this test alters the cache of dictionaries shared by all hyphenators
+	failToLoad := func(t *testing.T, patterns ...string) {
+		mx.Lock()
+		defer mx.Unlock()
+
+		if loadedPatterns == nil {
+			loadedPatterns = make(map[string]*Dictionary)
+		}
+
+		for _, toPin := range patterns {
+			key := toPin
+			saved, wasCached := loadedPatterns[key]
+			loadedPatterns[key] = nil // patterns which failed to load yield a nil dictionary
+
+			t.Cleanup(func() {
+				mx.Lock()
+				defer mx.Unlock()
+
+				if wasCached {
+					loadedPatterns[key] = saved
+				} else {
+					delete(loadedPatterns, key)
+				}
+			})
+		}
+	}
+
+	t.Run("should fall back to the default patterns", func(t *testing.T) {
+		failToLoad(t, "hyph-es.tex")
+
+		h := New(WithLanguage("es"))
+		require.Equal(t, "patterns: ushyphmax.tex", h.String())
+		require.Equal(t, toRunes([]string{"hy", "phen", "ated"}), h.BreakWordString("hyphenated"))
+	})
+
+	t.Run("should not break words without any patterns", func(t *testing.T) {
+		failToLoad(t, "hyph-es.tex", "ushyphmax.tex")
+
+		h := New(WithLanguage("es"))
+		require.Equal(t, toRunes([]string{"hyphenated"}), h.BreakWordString("hyphenated"))
+	})
+}
@@ -86,3 +86,63 @@ func BenchmarkRuneTrieGetPathKey(b *testing.B) {
 		trie.Get(pathKeys[i%len(pathKeys)])
 	}
 }
+
+// PackedTrie
+///////////////////////////////////////////////////////////////////////////////
+
+func BenchmarkPackedTrieGetStringKey(b *testing.B) {
+	trie := packedTrie(b, stringKeys[:])
+	b.ResetTimer()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		trie.Get(stringKeys[i%len(stringKeys)])
+	}
+}
+
+func BenchmarkPackedTrieGetPathKey(b *testing.B) {
+	trie := packedTrie(b, pathKeys[:])
+	b.ResetTimer()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		trie.Get(pathKeys[i%len(pathKeys)])
+	}
+}
+
+func BenchmarkPackedTrieLoad(b *testing.B) {
+	source := NewRuneTrie()
+	for i, key := range stringKeys {
+		source.Put(key, []byte{byte(i)})
+	}
+
+	data, err := Pack(source)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.ResetTimer()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := NewPackedTrie(data); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func packedTrie(b *testing.B, keys [][]rune) *PackedTrie {
+	source := NewRuneTrie()
+	for i, key := range keys {
+		source.Put(key, []byte{byte(i)})
+	}
+
+	data, err := Pack(source)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	trie, err := NewPackedTrie(data)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	return trie
+}
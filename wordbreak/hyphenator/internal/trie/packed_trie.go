@@ -0,0 +1,276 @@
+package trie
+
+import (
+	"encoding/binary"
+	"sort"
+)
+
+// PackedTrie is a compact, immutable trie of runes with []rune keys and []byte values.
+//
+// The trie is stored as packed arrays in a single slice of bytes, which may be
+// serialized with Pack then loaded with NewPackedTrie without any copy.
+//
+// Nodes are numbered in breadth-first order, so the children of a node are contiguous.
+// Edges are numbered like the nodes they lead to: the edge leading to node n is edge n-1.
+//
+// Layout (little endian), with integers stored on the minimal number of bytes (from 1 to 4):
+//
+//	magic   [4]byte             "RTP1"
+//	nodes   uint32              number of nodes
+//	pool    uint32              size of the pool of values, in bytes
+//	widths  [4]byte             width of edge indices, value offsets and runes (the last byte is unused)
+//	first   [nodes+1]uint       index of the first edge from every node
+//	values  [nodes]uint         offset of the value of every node in the pool (0 when no value)
+//	labels  [nodes-1]rune       rune for every edge, sorted for every node
+//	pool    [pool]byte          values, prefixed by their length
+type PackedTrie struct {
+	first  packedInts
+	values packedInts
+	labels packedInts
+	pool   []byte
+}
+
+// packedInts is an array of unsigned integers, stored on width bytes.
+type packedInts struct {
+	data  []byte
+	width int
+}
+
+type err string
+
+func (e err) Error() string {
+	return string(e)
+}
+
+const (
+	// ErrInvalidPackedTrie indicates that some data is not a PackedTrie serialized by Pack.
+	ErrInvalidPackedTrie err = "invalid packed trie"
+
+	// ErrInvalidPackedValue indicates that a value cannot be stored in a PackedTrie.
+	ErrInvalidPackedValue err = "packed trie values must be []byte of at most 255 bytes"
+)
+
+const (
+	packedMagic      = "RTP1"
+	packedHeaderSize = 16
+	maxPackedValue   = 255
+)
+
+// NewPackedTrie loads a PackedTrie from data serialized by Pack.
+//
+// The data is not copied, and must not be altered afterwards.
+func NewPackedTrie(data []byte) (*PackedTrie, error) {
+	if len(data) < packedHeaderSize || string(data[:4]) != packedMagic {
+		return nil, ErrInvalidPackedTrie
+	}
+
+	nodes := int(binary.LittleEndian.Uint32(data[4:]))
+	poolSize := int(binary.LittleEndian.Uint32(data[8:]))
+	indexWidth, offsetWidth, runeWidth := int(data[12]), int(data[13]), int(data[14])
+
+	for _, width := range []int{indexWidth, offsetWidth, runeWidth} {
+		if width < 1 || width > 4 {
+			return nil, ErrInvalidPackedTrie
+		}
+	}
+
+	if nodes == 0 || len(data) != packedHeaderSize+indexWidth*(nodes+1)+offsetWidth*nodes+runeWidth*(nodes-1)+poolSize {
+		return nil, ErrInvalidPackedTrie
+	}
+
+	offset := packedHeaderSize
+	section := func(size int) []byte {
+		s := data[offset : offset+size : offset+size]
+		offset += size
+
+		return s
+	}
+
+	trie := &PackedTrie{
+		first:  packedInts{data: section(indexWidth * (nodes + 1)), width: indexWidth},
+		values: packedInts{data: section(offsetWidth * nodes), width: offsetWidth},
+		labels: packedInts{data: section(runeWidth * (nodes - 1)), width: runeWidth},
+		pool:   section(poolSize),
+	}
+
+	if !trie.isValid(nodes) {
+		return nil, ErrInvalidPackedTrie
+	}
+
+	return trie, nil
+}
+
+// isValid checks that edge indices and value offsets remain within bounds,
+// so corrupted data cannot make Get read beyond the packed arrays.
+func (trie *PackedTrie) isValid(nodes int) bool {
+	edges := nodes - 1
+	if trie.first.at(0) != 0 || trie.first.at(nodes) != edges {
+		return false
+	}
+
+	for i := 1; i <= nodes; i++ {
+		if trie.first.at(i) < trie.first.at(i-1) {
+			return false
+		}
+	}
+
+	for i := 0; i < nodes; i++ {
+		offset := trie.values.at(i)
+		if offset == 0 {
+			continue
+		}
+
+		if offset >= len(trie.pool) || offset+1+int(trie.pool[offset]) > len(trie.pool) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Get returns the value stored at the given key.
+//
+// Returns nil for internal nodes.
+func (trie *PackedTrie) Get(key []rune) []byte {
+	var node int
+
+	for _, r := range key {
+		lo, hi := trie.first.at(node), trie.first.at(node+1)
+		end := hi
+
+		// binary search among the sorted labels of the children of this node
+		for lo < hi {
+			mid := int(uint(lo+hi) >> 1)
+			if rune(trie.labels.at(mid)) < r {
+				lo = mid + 1
+			} else {
+				hi = mid
+			}
+		}
+
+		if lo == end || rune(trie.labels.at(lo)) != r {
+			return nil
+		}
+
+		node = lo + 1
+	}
+
+	offset := trie.values.at(node)
+	if offset == 0 {
+		return nil
+	}
+
+	size := int(trie.pool[offset])
+
+	return trie.pool[offset+1 : offset+1+size : offset+1+size]
+}
+
+func (p packedInts) at(index int) int {
+	b := p.data[index*p.width:]
+
+	switch p.width {
+	case 1:
+		return int(b[0])
+	case 2:
+		return int(binary.LittleEndian.Uint16(b))
+	case 3:
+		return int(b[0]) | int(b[1])<<8 | int(b[2])<<16
+	default:
+		return int(binary.LittleEndian.Uint32(b))
+	}
+}
+
+// Pack serializes a RuneTrie with []byte values into the compact format of a PackedTrie.
+//
+// Values must be []byte of at most 255 bytes.
+func Pack(source *RuneTrie) ([]byte, error) {
+	type packedNode struct {
+		trie  *RuneTrie
+		label rune
+	}
+
+	// breadth-first traversal: children are sorted by rune
+	nodes := []packedNode{{trie: source}}
+	for i := 0; i < len(nodes); i++ {
+		children := nodes[i].trie.children
+		labels := make([]rune, 0, len(children))
+		for r := range children {
+			labels = append(labels, r)
+		}
+		sort.Slice(labels, func(a, b int) bool { return labels[a] < labels[b] })
+
+		for _, r := range labels {
+			nodes = append(nodes, packedNode{trie: children[r], label: r})
+		}
+	}
+
+	first := make([]int, 0, len(nodes)+1)
+	values := make([]int, 0, len(nodes))
+	labels := make([]int, 0, len(nodes))
+	pool := []byte{0} // offset 0 stands for no value
+
+	edges := 0
+	for i, node := range nodes {
+		first = append(first, edges)
+		edges += len(node.trie.children)
+		if i > 0 {
+			labels = append(labels, int(node.label))
+		}
+
+		if node.trie.value == nil {
+			values = append(values, 0)
+
+			continue
+		}
+
+		value, ok := node.trie.value.([]byte)
+		if !ok || len(value) > maxPackedValue {
+			return nil, ErrInvalidPackedValue
+		}
+
+		values = append(values, len(pool))
+		pool = append(pool, byte(len(value)))
+		pool = append(pool, value...)
+	}
+	first = append(first, edges)
+
+	indexWidth, offsetWidth, runeWidth := widthOf(first), widthOf(values), widthOf(labels)
+
+	data := make([]byte, 0, packedHeaderSize+indexWidth*len(first)+offsetWidth*len(values)+runeWidth*len(labels)+len(pool))
+	data = append(data, packedMagic...)
+	data = binary.LittleEndian.AppendUint32(data, uint32(len(nodes)))
+	data = binary.LittleEndian.AppendUint32(data, uint32(len(pool)))
+	data = append(data, byte(indexWidth), byte(offsetWidth), byte(runeWidth), 0)
+	data = appendInts(data, first, indexWidth)
+	data = appendInts(data, values, offsetWidth)
+	data = appendInts(data, labels, runeWidth)
+
+	return append(data, pool...), nil
+}
+
+// widthOf yields the number of bytes needed to store all the given unsigned integers.
+func widthOf(values []int) int {
+	var highest int
+	for _, value := range values {
+		if value > highest {
+			highest = value
+		}
+	}
+
+	width := 1
+	for highest >= 1<<(8*width) && width < 4 {
+		width++
+	}
+
+	return width
+}
+
+func appendInts(data []byte, values []int, width int) []byte {
+	for _, value := range values {
+		for i := 0; i < width; i++ {
+			data = append(data, byte(value>>(8*i)))
+		}
+	}
+
+	return data
+}
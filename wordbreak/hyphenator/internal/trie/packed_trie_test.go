@@ -0,0 +1,170 @@
+package trie
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"testing"
+)
+
+func TestPackedTrie(t *testing.T) {
+	cases := []struct {
+		key   []rune
+		value []byte
+	}{
+		{[]rune(""), []byte{9}},
+		{[]rune("fish"), []byte{0}},
+		{[]rune("/cat"), []byte{1}},
+		{[]rune("/dog"), []byte{2, 0}},
+		{[]rune("/cats"), []byte{3, 0, 1}},
+		{[]rune("/caterpillar"), []byte{4}},
+		{[]rune("/cat/gideon"), []byte{}},
+		{[]rune("/cat/giddy"), []byte{6, 6}},
+		{[]rune("été"), []byte{7}},
+	}
+	expectNilValues := [][]rune{[]rune("/"), []rune("/c"), []rune("/ca"), []rune("/other"), []rune("fishes"), []rune("ét")}
+
+	source := NewRuneTrie()
+	for _, c := range cases {
+		source.Put(c.key, c.value)
+	}
+
+	data, err := Pack(source)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	trie, err := NewPackedTrie(data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, c := range cases {
+		if value := trie.Get(c.key); value == nil || !bytes.Equal(value, c.value) {
+			t.Errorf("expected key %v to have value %v, got %v", string(c.key), c.value, value)
+		}
+	}
+
+	for _, key := range expectNilValues {
+		if value := trie.Get(key); value != nil {
+			t.Errorf("expected key %v to have value nil, got %v", string(key), value)
+		}
+	}
+
+	t.Run("should pack random keys", func(t *testing.T) {
+		source := NewRuneTrie()
+		for i, key := range stringKeys {
+			source.Put(key, []byte{byte(i)})
+		}
+
+		data, err := Pack(source)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		trie, err := NewPackedTrie(data)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		for _, key := range stringKeys {
+			expected := source.Get(key).([]byte)
+			if value := trie.Get(key); !bytes.Equal(value, expected) {
+				t.Errorf("expected key %v to have value %v, got %v", key, expected, value)
+			}
+		}
+	})
+
+	t.Run("should reject invalid data", func(t *testing.T) {
+		if _, err := NewPackedTrie(data[:len(data)-1]); !errors.Is(err, ErrInvalidPackedTrie) {
+			t.Errorf("expected truncated data to be rejected, got %v", err)
+		}
+
+		if _, err := NewPackedTrie([]byte("RTP0")); !errors.Is(err, ErrInvalidPackedTrie) {
+			t.Errorf("expected invalid data to be rejected, got %v", err)
+		}
+
+		for name, corrupt := range map[string]func([]byte){
+			"decreasing edge index":   func(corrupted []byte) { corrupted[packedHeaderSize+2] = 0 },
+			"edge index out of range": func(corrupted []byte) { corrupted[packedHeaderSize+1] = 0xff },
+			"value offset out of range": func(corrupted []byte) {
+				nodes := int(binary.LittleEndian.Uint32(corrupted[4:]))
+				corrupted[packedHeaderSize+int(corrupted[12])*(nodes+1)] = 0xff
+			},
+		} {
+			corrupted := append([]byte{}, data...)
+			corrupt(corrupted)
+
+			if _, err := NewPackedTrie(corrupted); !errors.Is(err, ErrInvalidPackedTrie) {
+				t.Errorf("expected data with %s to be rejected, got %v", name, err)
+			}
+		}
+
+		invalid := NewRuneTrie()
+		invalid.Put([]rune("a"), 1)
+		if _, err := Pack(invalid); !errors.Is(err, ErrInvalidPackedValue) {
+			t.Errorf("expected invalid value to be rejected, got %v", err)
+		}
+	})
+}
+
+func FuzzNewPackedTrie(f *testing.F) {
+	source := NewRuneTrie()
+	for i, key := range []string{"", "a", "ab", "abc", "b", "été"} {
+		source.Put([]rune(key), []byte{byte(i), 1})
+	}
+
+	data, err := Pack(source)
+	if err != nil {
+		f.Fatalf("unexpected error: %v", err)
+	}
+
+	f.Add(data)
+	f.Add(data[:packedHeaderSize])
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		trie, err := NewPackedTrie(data)
+		if err != nil {
+			if !errors.Is(err, ErrInvalidPackedTrie) {
+				t.Errorf("expected invalid data to be rejected with ErrInvalidPackedTrie, got %v", err)
+			}
+
+			return
+		}
+
+		// corrupted data which is accepted must not make lookups panic
+		for _, key := range []string{"", "a", "ab", "abc", "abcd", "b", "été", "z"} {
+			_ = trie.Get([]rune(key))
+		}
+	})
+}
+
+func TestRuneTrieWalk(t *testing.T) {
+	trie := NewRuneTrie()
+	for i, key := range []string{"b", "ab", "a", "abc", "c"} {
+		trie.Put([]rune(key), i)
+	}
+
+	var keys []string
+	trie.Walk(func(key []rune, _ interface{}) {
+		keys = append(keys, string(key))
+	})
+
+	if expected := []string{"a", "ab", "abc", "b", "c"}; !equalStrings(keys, expected) {
+		t.Errorf("expected keys %v, got %v", expected, keys)
+	}
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
@@ -1,5 +1,7 @@
 package trie
 
+import "sort"
+
 // RuneTrie is a trie of runes with []rune keys and interface{} values.
 //
 // Internal nodes have nil values: a stored nil value will thus not be distinguishable.
@@ -92,6 +94,29 @@ func (trie *RuneTrie) Delete(key []rune) bool {
 	return true // node (internal or not) existed and its value was nil'd
 }
 
+// Walk calls fn for every key with a value, in the order of the runes of the keys.
+//
+// The key passed to fn is reused by the walk: fn must copy it to retain it.
+func (trie *RuneTrie) Walk(fn func(key []rune, value interface{})) {
+	trie.walk(make([]rune, 0, 10), fn)
+}
+
+func (trie *RuneTrie) walk(key []rune, fn func(key []rune, value interface{})) {
+	if trie.value != nil {
+		fn(key, trie.value)
+	}
+
+	labels := make([]rune, 0, len(trie.children))
+	for r := range trie.children {
+		labels = append(labels, r)
+	}
+	sort.Slice(labels, func(i, j int) bool { return labels[i] < labels[j] })
+
+	for _, r := range labels {
+		trie.children[r].walk(append(key, r), fn)
+	}
+}
+
 // A node of the RuneTrie with its the rune key and child to descend into.
 type nodeRune struct {
 	node *RuneTrie
@@ -1,6 +1,11 @@
 package hyphenator
 
 import (
+	"fmt"
+	"io/fs"
+	"sync"
+
+	"github.com/fredbi/go-typeset/wordbreak/hyphenator/internal/trie"
 	"golang.org/x/text/language"
 )
 
@@ -17,13 +22,165 @@ var (
 		language.LatinAmericanSpanish,
 	}
 
-	langMatcher = language.NewMatcher(supportedLanguages)
+	langMx sync.RWMutex
+
+	// languages known to the matcher: supported languages, then registered ones
+	matchedLanguages = supportedLanguages
+	langMatcher      = language.NewMatcher(matchedLanguages)
+
+	// dictionaries registered for a language, which take precedence over embedded patterns
+	registeredDictionaries = make(map[language.Tag]*Dictionary)
+
+	// pattern files registered for a language, loaded on first use
+	registeredPatterns = make(map[language.Tag]patternsSource)
 )
 
-func langToPattern(tag language.Tag) string {
-	matched, _, _ := langMatcher.Match(tag)
+type patternsSource struct {
+	fsys fs.FS
+	name string
+}
+
+// RegisterDictionary registers a Dictionary for a language.
+//
+// Hyphenators configured with this language, or with a language that matches it best,
+// use this dictionary. A registered dictionary takes precedence over the embedded patterns
+// for the same language.
+//
+// Example:
+//
+//	dict, err := hyphenator.LoadPatternsFS(os.DirFS("patterns"), "hyph-it.tex")
+//	if err != nil {
+//		return err
+//	}
+//	hyphenator.RegisterDictionary(language.Italian, dict)
+//	h := hyphenator.New(hyphenator.WithLanguage("it"))
+func RegisterDictionary(tag language.Tag, dict *Dictionary) {
+	langMx.Lock()
+	defer langMx.Unlock()
+
+	registerLanguage(tag)
+	delete(registeredPatterns, tag)
+	registeredDictionaries[tag] = dict
+}
+
+// RegisterPatterns registers a pattern file for a language, to be loaded from a file system on first use.
+//
+// This is how packages generated by languages/gen_packages.go make their patterns available,
+// so that programs only embed the languages they import, e.g.:
+//
+//	//go:embed hyph-it.bin
+//	var patterns embed.FS
+//
+//	func init() {
+//		hyphenator.RegisterPatterns(language.Italian, patterns, "hyph-it.bin")
+//	}
+//
+// A pattern file that cannot be loaded is ignored, and the default patterns are used instead.
+func RegisterPatterns(tag language.Tag, fsys fs.FS, name string) {
+	langMx.Lock()
+	defer langMx.Unlock()
+
+	registerLanguage(tag)
+	delete(registeredDictionaries, tag)
+	registeredPatterns[tag] = patternsSource{fsys: fsys, name: name}
+}
+
+// registerLanguage makes a language known to the matcher.
+func registerLanguage(tag language.Tag) {
+	if isRegistered(tag) || isSupported(tag) {
+		return
+	}
+
+	matched := make([]language.Tag, len(matchedLanguages), len(matchedLanguages)+1)
+	copy(matched, matchedLanguages)
+	matchedLanguages = append(matched, tag)
+	langMatcher = language.NewMatcher(matchedLanguages)
+}
+
+func isRegistered(tag language.Tag) bool {
+	_, hasDictionary := registeredDictionaries[tag]
+	_, hasPatterns := registeredPatterns[tag]
+
+	return hasDictionary || hasPatterns
+}
+
+// matchLanguage yields the supported or registered language that best matches a language tag.
+func matchLanguage(tag language.Tag) language.Tag {
+	langMx.RLock()
+	defer langMx.RUnlock()
+
+	_, index, _ := langMatcher.Match(tag)
+
+	return matchedLanguages[index]
+}
 
-	switch matched {
+// matchLanguageStrings is like matchLanguage, but takes language strings like "en-US" or "fr".
+func matchLanguageStrings(lang ...string) language.Tag {
+	langMx.RLock()
+	defer langMx.RUnlock()
+
+	_, index := language.MatchStrings(langMatcher, lang...)
+
+	return matchedLanguages[index]
+}
+
+func isSupported(tag language.Tag) bool {
+	for _, supported := range supportedLanguages {
+		if tag == supported {
+			return true
+		}
+	}
+
+	return false
+}
+
+// langToDictionary yields the registered dictionary for a language, or the one loaded
+// from the embedded patterns.
+//
+// It yields nil when the embedded patterns fail to load.
+func langToDictionary(tag language.Tag) *Dictionary {
+	matched := matchLanguage(tag)
+
+	langMx.RLock()
+	dict, hasDictionary := registeredDictionaries[matched]
+	source, hasPatterns := registeredPatterns[matched]
+	langMx.RUnlock()
+
+	if hasDictionary {
+		return dict
+	}
+
+	if hasPatterns {
+		key := fmt.Sprintf("%v:%s", matched, source.name)
+		dict = loadFromCache(key, func() (*Dictionary, error) {
+			return LoadPatternsFS(source.fsys, source.name)
+		})
+
+		if dict != nil {
+			return dict
+		}
+	}
+
+	return loadDictFromCache(langToPattern(matched))
+}
+
+// defaultDictionary yields the dictionary for American English.
+//
+// If even these patterns fail to load, it yields an empty dictionary, which never breaks words.
+func defaultDictionary() *Dictionary {
+	if dict := loadDictFromCache(langToPattern(language.AmericanEnglish)); dict != nil {
+		return dict
+	}
+
+	return &Dictionary{
+		exceptions: trie.NewRuneTrie(),
+		patterns:   runePatterns{trie: trie.NewRuneTrie()},
+		Identifier: defaultIdentifier,
+	}
+}
+
+func langToPattern(tag language.Tag) string {
+	switch matchLanguage(tag) {
 	case language.BritishEnglish, language.English:
 		return "hyph-en-gb.tex"
 	case language.Spanish, language.EuropeanSpanish, language.LatinAmericanSpanish:
@@ -0,0 +1,40 @@
+//go:build ignore
+
+// compile_patterns compiles the stripped pattern files in this folder into their compact binary form,
+// so the hyphenator loads them quickly.
+package main
+
+import (
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/fredbi/go-typeset/wordbreak/hyphenator"
+)
+
+func main() {
+	files, err := filepath.Glob("*.tex")
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	for _, file := range files {
+		if err := compile(file); err != nil {
+			log.Fatal(err)
+		}
+	}
+}
+
+func compile(file string) error {
+	dict, err := hyphenator.LoadPatternsFS(os.DirFS("."), file)
+	if err != nil {
+		return err
+	}
+
+	compiled, err := dict.MarshalBinary()
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(hyphenator.CompiledName(file), compiled, 0o600)
+}
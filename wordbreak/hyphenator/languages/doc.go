@@ -1,3 +1,5 @@
-//go:generate go run strip_comments.go
+//go:generate go run -tags bootstrap strip_comments.go
+//go:generate go run -tags bootstrap compile_patterns.go
+//go:generate go run -tags bootstrap gen_packages.go -source tex/hyph-utf8
 
 package languages
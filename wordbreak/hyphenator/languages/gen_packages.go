@@ -0,0 +1,211 @@
+//go:build ignore
+
+// gen_packages generates a package for every hyph-utf8 language found in the source folder.
+//
+// Every generated package embeds the compiled patterns for its language, and registers them
+// with the hyphenator when imported.
+//
+// Usage:
+//
+//	go run gen_packages.go -source tex/hyph-utf8 [-output .]
+//
+// The source folder is expected to hold pattern files from the hyph-utf8 collection
+// (https://github.com/hyphenation/tex-hyphen/tree/master/hyph-utf8/tex/generic/hyph-utf8/patterns/tex).
+// Missing files are skipped.
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"go/format"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"text/template"
+
+	"github.com/fredbi/go-typeset/wordbreak/hyphenator"
+)
+
+type languageT struct {
+	Package string // name of the generated package
+	Tag     string // BCP 47 language tag
+	File    string // hyph-utf8 pattern file
+	Name    string // language name, from the header of the pattern file
+
+	Compiled string // compiled pattern file
+}
+
+var (
+	headerName = regexp.MustCompile(`^%\s+name:\s*(.+)$`)
+
+	// languages supported by the hyph-utf8 collection which are not embedded by default
+	languages = []languageT{
+		{Package: "bg", Tag: "bg", File: "hyph-bg.tex"},
+		{Package: "ca", Tag: "ca", File: "hyph-ca.tex"},
+		{Package: "cs", Tag: "cs", File: "hyph-cs.tex"},
+		{Package: "da", Tag: "da", File: "hyph-da.tex"},
+		{Package: "el", Tag: "el", File: "hyph-el-monoton.tex"},
+		{Package: "et", Tag: "et", File: "hyph-et.tex"},
+		{Package: "fi", Tag: "fi", File: "hyph-fi.tex"},
+		{Package: "hr", Tag: "hr", File: "hyph-hr.tex"},
+		{Package: "hu", Tag: "hu", File: "hyph-hu.tex"},
+		{Package: "it", Tag: "it", File: "hyph-it.tex"},
+		{Package: "lt", Tag: "lt", File: "hyph-lt.tex"},
+		{Package: "lv", Tag: "lv", File: "hyph-lv.tex"},
+		{Package: "nb", Tag: "nb", File: "hyph-nb.tex"},
+		{Package: "nl", Tag: "nl", File: "hyph-nl.tex"},
+		{Package: "nn", Tag: "nn", File: "hyph-nn.tex"},
+		{Package: "pl", Tag: "pl", File: "hyph-pl.tex"},
+		{Package: "pt", Tag: "pt", File: "hyph-pt.tex"},
+		{Package: "ro", Tag: "ro", File: "hyph-ro.tex"},
+		{Package: "ru", Tag: "ru", File: "hyph-ru.tex"},
+		{Package: "sk", Tag: "sk", File: "hyph-sk.tex"},
+		{Package: "sl", Tag: "sl", File: "hyph-sl.tex"},
+		{Package: "sv", Tag: "sv", File: "hyph-sv.tex"},
+		{Package: "tr", Tag: "tr", File: "hyph-tr.tex"},
+		{Package: "uk", Tag: "uk", File: "hyph-uk.tex"},
+	}
+
+	packageTemplate = template.Must(template.New("package").Parse(`// Code generated by gen_packages.go. DO NOT EDIT.
+
+// Package {{ .Package }} registers the {{ .Name }} hyphenation patterns from the hyph-utf8 collection.
+//
+// Import this package for its side effects, so the hyphenator supports this language:
+//
+//	import _ "github.com/fredbi/go-typeset/wordbreak/hyphenator/languages/{{ .Package }}"
+//
+// The license of the patterns is found in the LICENSE file of this package.
+package {{ .Package }}
+
+import (
+	"embed"
+
+	"github.com/fredbi/go-typeset/wordbreak/hyphenator"
+	"golang.org/x/text/language"
+)
+
+//go:embed {{ .Compiled }}
+var texFS embed.FS
+
+func init() {
+	hyphenator.RegisterPatterns(language.MustParse("{{ .Tag }}"), texFS, "{{ .Compiled }}")
+}
+`))
+)
+
+func main() {
+	source := flag.String("source", filepath.Join("tex", "hyph-utf8"), "folder with hyph-utf8 pattern files")
+	output := flag.String("output", ".", "folder where to generate the language packages")
+	flag.Parse()
+
+	if _, err := os.Stat(*source); errors.Is(err, fs.ErrNotExist) {
+		log.Printf("no hyph-utf8 pattern files in %q: skipped", *source)
+
+		return
+	}
+
+	for _, lang := range languages {
+		if err := generate(lang, *source, *output); err != nil {
+			log.Fatal(err)
+		}
+	}
+}
+
+func generate(lang languageT, source, output string) error {
+	original, err := os.ReadFile(filepath.Join(source, lang.File))
+	if errors.Is(err, fs.ErrNotExist) {
+		log.Printf("no pattern file for %s: skipped", lang.Tag)
+
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	dict, err := hyphenator.LoadPatternsFrom(bytes.NewReader(original))
+	if err != nil {
+		return fmt.Errorf("%s: %w", lang.File, err)
+	}
+
+	compiled, err := dict.MarshalBinary()
+	if err != nil {
+		return fmt.Errorf("%s: %w", lang.File, err)
+	}
+
+	header := licenseHeader(original)
+	lang.Name = languageName(header, lang.Tag)
+
+	folder := filepath.Join(output, lang.Package)
+	if err = os.MkdirAll(folder, 0o755); err != nil {
+		return err
+	}
+
+	lang.Compiled = hyphenator.CompiledName(lang.File)
+	if err = os.WriteFile(filepath.Join(folder, lang.Compiled), compiled, 0o600); err != nil {
+		return err
+	}
+
+	if err = os.WriteFile(filepath.Join(folder, "LICENSE"), header, 0o600); err != nil {
+		return err
+	}
+
+	var code bytes.Buffer
+	if err = packageTemplate.Execute(&code, lang); err != nil {
+		return err
+	}
+
+	formatted, err := format.Source(code.Bytes())
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(filepath.Join(folder, "doc.go"), formatted, 0o600)
+}
+
+// licenseHeader retains the leading comments of a pattern file, with copyright and license notices.
+func licenseHeader(original []byte) []byte {
+	var h bytes.Buffer
+
+	scanner := bufio.NewScanner(bytes.NewReader(original))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if len(strings.TrimSpace(line)) == 0 {
+			continue
+		}
+
+		if !strings.HasPrefix(line, "%") {
+			break
+		}
+
+		fmt.Fprintln(&h, strings.TrimPrefix(strings.TrimPrefix(line, "%"), " "))
+	}
+
+	return h.Bytes()
+}
+
+// languageName finds the name of the language in the header of a hyph-utf8 pattern file.
+func languageName(header []byte, tag string) string {
+	var inLanguage bool
+
+	scanner := bufio.NewScanner(bytes.NewReader(header))
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		switch {
+		case strings.HasPrefix(line, "language:"):
+			inLanguage = true
+		case inLanguage:
+			if name := headerName.FindStringSubmatch("%" + line); len(name) > 1 {
+				return strings.TrimSpace(name[1])
+			}
+		}
+	}
+
+	return tag
+}
@@ -11,12 +11,25 @@ import (
 	"path/filepath"
 	"regexp"
 	"strings"
+
+	"github.com/fredbi/go-typeset/wordbreak/hyphenator"
 )
 
-var texComment = regexp.MustCompile(`^(.+?)\s*%.*$`)
+var (
+	texComment = regexp.MustCompile(`^(.+?)\s*%.*$`)
+
+	// hyphenmins for pattern files which don't specify them
+	defaultHyphenMins = map[string][2]int{
+		"ushyphmax.tex": {2, 3}, // plain TeX settings for US English
+	}
+)
 
 func main() {
 	if err := filepath.WalkDir("tex", func(pth string, d fs.DirEntry, err error) error {
+		if d.IsDir() && pth != "tex" {
+			return filepath.SkipDir // e.g. hyph-utf8 files for language packages
+		}
+
 		if d.IsDir() || !strings.HasSuffix(d.Name(), ".tex") {
 			return nil
 		}
@@ -44,6 +57,21 @@ func strip(pth string) error {
 		_ = output.Close()
 	}()
 
+	// the hyphenmins specified in the header comments are retained as TeX commands
+	dict, err := hyphenator.LoadPatternsFS(os.DirFS(filepath.Dir(pth)), destination)
+	if err != nil {
+		return err
+	}
+
+	left, right := dict.LeftHyphenMin, dict.RightHyphenMin
+	if hyphenmins, ok := defaultHyphenMins[destination]; ok && left == 0 && right == 0 {
+		left, right = hyphenmins[0], hyphenmins[1]
+	}
+
+	if left > 0 && right > 0 {
+		fmt.Fprintf(output, "\\lefthyphenmin=%d \\righthyphenmin=%d\n", left, right)
+	}
+
 	scanner := bufio.NewScanner(file)
 	for scanner.Scan() {
 		line := scanner.Text()
@@ -10,9 +10,13 @@ type (
 
 	options struct {
 		lang      language.Tag
+		dict      *Dictionary
 		minLength int
 		minLeft   int
 		minRight  int
+
+		hasMinLeft  bool
+		hasMinRight bool
 	}
 )
 
@@ -35,10 +39,18 @@ func WithLanguageTag(tag language.Tag) Option {
 //
 // Unsupported languages are matched to sensible defaults, using a language.Matcher.
 func WithLanguage(lang string) Option {
-	tag, _ := language.MatchStrings(langMatcher, lang)
+	return func(o *options) {
+		o.lang = matchLanguageStrings(lang)
+	}
+}
 
+// WithDictionary specifies the Dictionary of hyphenation patterns and exceptions used by the hyphenator,
+// e.g. a Dictionary loaded with LoadPatternsFS from user-provided pattern files.
+//
+// This overrides the dictionary selected by the language of the hyphenator.
+func WithDictionary(dict *Dictionary) Option {
 	return func(o *options) {
-		o.lang = tag
+		o.dict = dict
 	}
 }
 
@@ -53,19 +65,23 @@ func WithMinLength(minLength int) Option {
 
 // WithMinLeft configures the minimum length of a word part before an hyphenation point.
 //
-// The default is 2, meaning that the hyphenator will never break words leaving a single rune to the left.
+// The default is the "lefthyphenmin" of the patterns for the language, or 2 when the patterns
+// don't specify it, meaning that the hyphenator will never break words leaving a single rune to the left.
 func WithMinLeft(minLeft int) Option {
 	return func(o *options) {
 		o.minLeft = minLeft
+		o.hasMinLeft = true
 	}
 }
 
-// WithMinRight configures the minimum length of a word part after an hyphenation point.
+// WithMinRight configures the minimum length of the word part after the last hyphenation point.
 //
-// The default is 2, meaning that the hyphenator will never break words leaving a single rune to the right.
+// The default is the "righthyphenmin" of the patterns for the language (e.g. 3 for English), or 2 when
+// the patterns don't specify it, meaning that the hyphenator will never break words leaving a single rune to the right.
 func WithMinRight(minRight int) Option {
 	return func(o *options) {
 		o.minRight = minRight
+		o.hasMinRight = true
 	}
 }
 
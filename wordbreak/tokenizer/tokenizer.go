@@ -6,6 +6,18 @@ import (
 	"github.com/fredbi/go-typeset/terminal/runes"
 )
 
+// No-break spaces, which never separate tokens.
+const (
+	// NoBreakSpace (U+00A0)
+	NoBreakSpace = '\u00a0'
+
+	// FigureSpace (U+2007) is a no-break space as wide as a digit, used to line up numbers.
+	FigureSpace = '\u2007'
+
+	// NarrowNoBreakSpace (U+202F)
+	NarrowNoBreakSpace = '\u202f'
+)
+
 // Tokenizer breaks a text into space-separated tokens.
 type Tokenizer struct {
 }
@@ -17,14 +29,26 @@ func New() *Tokenizer {
 
 // BreakWord breaks a string into a slice of blank-separated tokens.
 //
-// Token separators are from the class unicode.IsSpace
+// Token separators are from the class unicode.IsSpace, except no-break spaces (see IsSeparator).
 //
 // Blank separators are not retained in the result.
 func (t *Tokenizer) BreakWord(word []rune) [][]rune {
-	return runes.FieldsFunc(word, unicode.IsSpace)
+	return runes.FieldsFunc(word, IsSeparator)
 }
 
 // BreakWordString is the same as BreakWord but takes a string as input.
 func (t *Tokenizer) BreakWordString(word string) [][]rune {
 	return t.BreakWord([]rune(word))
 }
+
+// IsSeparator tells if a rune separates tokens.
+//
+// No-break spaces (U+00A0, U+2007 and U+202F) are retained inside tokens.
+func IsSeparator(r rune) bool {
+	switch r {
+	case NoBreakSpace, FigureSpace, NarrowNoBreakSpace:
+		return false
+	default:
+		return unicode.IsSpace(r)
+	}
+}
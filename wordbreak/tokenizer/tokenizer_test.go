@@ -28,6 +28,17 @@ func TestBreakWord(t *testing.T) {
 			toStrings(s.BreakWord([]rune(word))),
 		)
 	})
+
+	t.Run("should retain no-break spaces", func(t *testing.T) {
+		const word = "Fig.\u00a03 and 10\u202fkm or 1\u2007000"
+
+		s := New()
+		require.Equal(t, []string{
+			"Fig.\u00a03", "and", "10\u202fkm", "or", "1\u2007000",
+		},
+			toStrings(s.BreakWord([]rune(word))),
+		)
+	})
 }
 
 func toStrings(in [][]rune) []string {